@@ -0,0 +1,194 @@
+package julia
+
+import "fmt"
+
+// Handle is a reference to a value held by a backend
+type Handle interface {
+	// Type evaluates to julia representation of typeof
+	Type() string
+}
+
+// Backend abstracts julia execution so that code depending on this
+// package can swap the embedded runtime, a worker process or a fake
+// implementation for tests. Handles are only valid with the backend
+// that created them.
+type Backend interface {
+	// Eval evaluates input as if it were julia code
+	Eval(input string) (Handle, error)
+
+	// EvalFunc evaluates a function by its name in the module passing args to it
+	EvalFunc(name string, moduleType ModuleType, args ...Handle) (Handle, error)
+
+	// Marshal packs x into a value that can be passed to julia
+	Marshal(x any) (Handle, error)
+
+	// Unmarshal unpacks value into x
+	Unmarshal(data Handle, x any) error
+
+	// TypeOf returns julia representation of typeof
+	TypeOf(data Handle) (string, error)
+}
+
+var (
+	_ Backend = (*embeddedBackend)(nil)
+	_ Backend = (*workerBackend)(nil)
+	_ Backend = (*FakeBackend)(nil)
+)
+
+// NewEmbeddedBackend returns a backend for the runtime embedded in this
+// process. If r is nil, backend uses the runtime package level functions
+// forward to
+func NewEmbeddedBackend(r *Runtime) Backend {
+	return &embeddedBackend{rt: r}
+}
+
+// NewWorkerBackend returns a backend for julia worker process
+func NewWorkerBackend(w *Worker) Backend {
+	return &workerBackend{w: w}
+}
+
+// embeddedBackend adapts Runtime to Backend
+type embeddedBackend struct {
+	rt *Runtime
+}
+
+func (b *embeddedBackend) runtime() *Runtime {
+	if b.rt == nil {
+		return current()
+	}
+
+	return b.rt
+}
+
+func (b *embeddedBackend) Eval(input string) (Handle, error) {
+	value, err := b.runtime().Eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (b *embeddedBackend) EvalFunc(name string, moduleType ModuleType, args ...Handle) (Handle, error) {
+	values := make([]*Value, len(args))
+	for i, arg := range args {
+		value, err := b.value(arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	value, err := b.runtime().EvalFunc(name, moduleType, values...)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (b *embeddedBackend) Marshal(x any) (Handle, error) {
+	value, err := b.runtime().Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (b *embeddedBackend) Unmarshal(data Handle, x any) error {
+	value, err := b.value(data)
+	if err != nil {
+		return err
+	}
+
+	return b.runtime().Unmarshal(value, x)
+}
+
+func (b *embeddedBackend) TypeOf(data Handle) (string, error) {
+	value, err := b.value(data)
+	if err != nil {
+		return "", err
+	}
+
+	return value.Type(), nil
+}
+
+// value asserts handle to be a value of the embedded runtime
+func (b *embeddedBackend) value(h Handle) (*Value, error) {
+	value, ok := h.(*Value)
+	if !ok || value == nil {
+		return nil, fmt.Errorf("invalid handle %T, not created by embedded backend", h)
+	}
+
+	return value, nil
+}
+
+// workerBackend adapts Worker to Backend
+type workerBackend struct {
+	w *Worker
+}
+
+func (b *workerBackend) Eval(input string) (Handle, error) {
+	value, err := b.w.Eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (b *workerBackend) EvalFunc(name string, moduleType ModuleType, args ...Handle) (Handle, error) {
+	values := make([]*WorkerValue, len(args))
+	for i, arg := range args {
+		value, err := b.value(arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	value, err := b.w.EvalFunc(name, moduleType, values...)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (b *workerBackend) Marshal(x any) (Handle, error) {
+	value, err := b.w.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (b *workerBackend) Unmarshal(data Handle, x any) error {
+	value, err := b.value(data)
+	if err != nil {
+		return err
+	}
+
+	return b.w.Unmarshal(value, x)
+}
+
+func (b *workerBackend) TypeOf(data Handle) (string, error) {
+	value, err := b.value(data)
+	if err != nil {
+		return "", err
+	}
+
+	return value.Type(), nil
+}
+
+// value asserts handle to be a value of the worker
+func (b *workerBackend) value(h Handle) (*WorkerValue, error) {
+	value, ok := h.(*WorkerValue)
+	if !ok || value == nil {
+		return nil, fmt.Errorf("invalid handle %T, not created by worker backend", h)
+	}
+
+	return value, nil
+}
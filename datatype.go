@@ -0,0 +1,123 @@
+package julia
+
+/*
+#include <stdlib.h>
+#include "jlapi.h"
+*/
+import "C"
+import "unsafe"
+
+// DataType describes a julia type, such as the type of a value. It is
+// read via julia C API, so that callers do not need to parse julia
+// representation of types such as Array{Int8, 3}.
+//
+// Type parameters and field types that are not data types, such as
+// dimensions of an array or union types, are described only by their
+// julia representation returned by String, i.e. their Name is empty.
+type DataType struct {
+	// Name is the name of the type without parameters, such as Array
+	Name string
+
+	// Module is the name of the module the type is defined in, such as Core
+	Module string
+
+	// Parameters are type parameters, such as Int8 and 3 of Array{Int8, 3}
+	Parameters []*DataType
+
+	// FieldNames are names of fields of a concrete type. Fields of tuples
+	// are named by their position
+	FieldNames []string
+
+	// FieldTypes are types of fields in the order of FieldNames
+	FieldTypes []*DataType
+
+	// IsBitsType reports whether the type is immutable and contains no
+	// references to other values, i.e. isbitstype
+	IsBitsType bool
+
+	// IsMutable reports whether the type is mutable, i.e. ismutabletype
+	IsMutable bool
+
+	// IsAbstract reports whether the type is abstract, i.e. isabstracttype
+	IsAbstract bool
+
+	repr string
+}
+
+// String returns julia representation of the type, such as Array{Int8, 3}
+func (d *DataType) String() string {
+	if d == nil {
+		return ""
+	}
+
+	return d.repr
+}
+
+// newDataType describes julia type t
+func newDataType(t *C.jl_value_t) *DataType {
+	return describe(t, make(map[*C.jl_value_t]*DataType))
+}
+
+// describe describes julia type t. types described so far are tracked
+// in seen, since a type may refer to itself via its fields
+func describe(t *C.jl_value_t, seen map[*C.jl_value_t]*DataType) *DataType {
+	if d, ok := seen[t]; ok {
+		return d
+	}
+
+	d := &DataType{repr: repr(t)}
+	seen[t] = d
+
+	if C.gojl_is_datatype(t) == 0 {
+		return d
+	}
+
+	d.Name = C.GoString(C.gojl_type_name(t))
+	d.Module = C.GoString(C.gojl_type_module(t))
+	d.IsBitsType = C.gojl_is_bits_type(t) != 0
+	d.IsMutable = C.gojl_is_mutable_type(t) != 0
+	d.IsAbstract = C.gojl_is_abstract_type(t) != 0
+
+	// parameters and field types are referenced by t, which is the type
+	// of a rooted value or is referenced by such a type
+	params := getField(t, "parameters")
+	for i := 0; i < int(C.gojl_svec_len(params)); i++ {
+		d.Parameters = append(d.Parameters, describe(C.gojl_svec_ref(params, C.size_t(i)), seen))
+	}
+
+	// fields are defined for concrete types only
+	if C.gojl_is_concrete_type(t) == 0 {
+		return d
+	}
+
+	f, err := getFunction(jlFieldNames, ModuleMain)
+	if err != nil {
+		return d
+	}
+
+	names, err := call(f, t)
+	if err != nil {
+		return d
+	}
+
+	// names are copied before describing field types, which allocates,
+	// since names are not referenced by julia
+	for i := 0; i < int(C.gojl_svec_len(names.value)); i++ {
+		d.FieldNames = append(d.FieldNames, C.GoString(C.gojl_symbol_name(C.gojl_svec_ref(names.value, C.size_t(i)))))
+	}
+
+	types := C.gojl_field_types(t)
+	for i := range d.FieldNames {
+		d.FieldTypes = append(d.FieldTypes, describe(C.gojl_svec_ref(types, C.size_t(i)), seen))
+	}
+
+	return d
+}
+
+// getField returns field of julia struct by its name, which must exist
+func getField(v *C.jl_value_t, name string) *C.jl_value_t {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	return C.jl_get_field(v, cName)
+}
@@ -0,0 +1,77 @@
+package julia
+
+import "testing"
+
+func TestDataTypeParameters(t *testing.T) {
+	x, err := Eval("zeros(Int8, 2, 3, 4)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Release()
+
+	dataType := x.TypeOf()
+	if dataType.Name != "Array" || dataType.Module != "Core" {
+		t.Fatal("expected Core.Array, got", dataType.Module, dataType.Name)
+	}
+
+	if len(dataType.Parameters) != 2 {
+		t.Fatal("expected 2 parameters, got", len(dataType.Parameters))
+	}
+
+	if el := dataType.Parameters[0]; el.Name != "Int8" || !el.IsBitsType {
+		t.Fatal("expected Int8 bits type parameter, got", el)
+	}
+
+	// dimensions are not a type, hence described only by repr
+	if n := dataType.Parameters[1]; n.Name != "" || n.String() != "3" {
+		t.Fatal("expected 3 dimensions parameter, got", n)
+	}
+
+	if !dataType.IsMutable || dataType.IsAbstract || dataType.IsBitsType {
+		t.Fatal("expected mutable concrete type")
+	}
+}
+
+func TestDataTypeFields(t *testing.T) {
+	x, err := Eval("1//3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Release()
+
+	dataType := x.TypeOf()
+	if dataType.String() != "Rational{Int64}" || dataType.Module != "Base" {
+		t.Fatal("expected Base.Rational{Int64}, got", dataType.Module, dataType)
+	}
+
+	if len(dataType.FieldNames) != 2 || dataType.FieldNames[0] != "num" || dataType.FieldNames[1] != "den" {
+		t.Fatal("expected fields num and den, got", dataType.FieldNames)
+	}
+
+	for _, fieldType := range dataType.FieldTypes {
+		if fieldType.Name != "Int64" {
+			t.Fatal("expected Int64 field, got", fieldType)
+		}
+	}
+
+	if !dataType.IsBitsType || dataType.IsMutable {
+		t.Fatal("expected immutable bits type")
+	}
+}
+
+func TestDataTypeAbstract(t *testing.T) {
+	x, err := Eval("[1, 2.5, \"a\"]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Release()
+
+	el, err := x.Eltype()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if el.Name != "Any" || !el.IsAbstract || len(el.FieldNames) != 0 {
+		t.Fatal("expected abstract Any without fields, got", el)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build julia_dlopen
+
+package julia
+
+/*
+// libjulia is opened when the runtime is initialized, therefore neither
+// julia headers nor libjulia are required to build or start the binary
+#cgo CFLAGS: -DJULIA_DLOPEN
+#cgo LDFLAGS: -ldl
+#include <stdlib.h>
+#include "jlapi.h"
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// loadLibrary opens libjulia located via options and resolves julia
+// C API symbols used by this package
+func loadLibrary(opts *Options) error {
+	path := C.CString(opts.library())
+	defer C.free(unsafe.Pointer(path))
+
+	if msg := C.gojl_load(path); msg != nil {
+		return fmt.Errorf("%w: %s", ErrJuliaNotFound, C.GoString(msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package julia
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotInitialized is returned when runtime is used before it is initialized
+	ErrNotInitialized = errors.New("julia runtime not initialized")
+
+	// ErrFinalized is returned when runtime is used after it is finalized
+	ErrFinalized = errors.New("julia runtime finalized")
+
+	// ErrAlreadyInitialized is returned when initializing a runtime while
+	// another runtime is already initialized in this process
+	ErrAlreadyInitialized = errors.New("julia runtime already initialized by another runtime")
+
+	// ErrJuliaNotFound is returned when libjulia cannot be loaded while
+	// initializing a runtime of a binary built with julia_dlopen tag
+	ErrJuliaNotFound = errors.New("julia not found")
+)
+
+var (
+	// ErrWorkerCrashed is returned when julia worker process terminates
+	// while serving a request. Worker restarts the process on next call
+	ErrWorkerCrashed = errors.New("julia worker crashed")
+
+	// ErrWorkerUnresponsive is returned when julia worker process does not
+	// respond to a health check in time, in which case it is killed
+	ErrWorkerUnresponsive = errors.New("julia worker unresponsive")
+
+	// ErrWorkerClosed is returned when worker is used after it is closed
+	ErrWorkerClosed = errors.New("julia worker closed")
+
+	// ErrStaleValue is returned when using a value created by a worker
+	// process that has since terminated
+	ErrStaleValue = errors.New("value belongs to a terminated julia worker process")
+
+	// ErrPoolClosed is returned when worker pool is used after it is closed
+	ErrPoolClosed = errors.New("julia worker pool closed")
+)
+
+// ErrReleased is returned when using a value after it is released
+var ErrReleased = errors.New("julia value released")
+
+// ErrTypeMismatch is returned when runtime julia type of a value does
+// not match go type it is being unmarshaled into
+var ErrTypeMismatch = errors.New("type mismatch")
+
+// ErrShapeMismatch is returned when dimensions of a julia array do not
+// match those of a preallocated Mat it is being unmarshaled into
+var ErrShapeMismatch = errors.New("shape mismatch")
+
+// JuliaError represents an exception thrown by julia runtime while
+// evaluating code or calling a function. Use errors.As to inspect
+// the exception type name:
+//
+//	var jerr *julia.JuliaError
+//	if errors.As(err, &jerr) && jerr.Type == "DomainError" {
+//		...
+//	}
+type JuliaError struct {
+	// Type is the name of exception type, such as MethodError
+	Type string
+	// Message is the output of showerror on the exception
+	Message string
+	// Backtrace is the julia backtrace captured when exception was thrown
+	Backtrace string
+}
+
+func (e *JuliaError) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("julia exception: %s", e.Type)
+	}
+
+	return fmt.Sprintf("julia exception: %s", e.Message)
+}
+
+// Is reports whether target is a JuliaError of the same exception type,
+// allowing errors.Is(err, &julia.JuliaError{Type: "SingularException"})
+func (e *JuliaError) Is(target error) bool {
+	t, ok := target.(*JuliaError)
+	if !ok {
+		return false
+	}
+
+	return e.Type == t.Type
+}
@@ -0,0 +1,57 @@
+package julia
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvalFuncDomainError(t *testing.T) {
+	arg, err := Marshal(float64(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = EvalFunc("sqrt", ModuleBase, arg)
+	if err == nil {
+		t.Fatal("expected sqrt(-1.0) to throw")
+	}
+
+	var jErr *JuliaError
+	if !errors.As(err, &jErr) {
+		t.Fatal("expected *JuliaError, got", err)
+	}
+
+	if jErr.Type != "DomainError" {
+		t.Fatal("expected DomainError, got", jErr.Type)
+	}
+
+	if len(jErr.Message) == 0 || len(jErr.Backtrace) == 0 {
+		t.Fatal("expected error message and backtrace to be populated")
+	}
+}
+
+func TestEvalFuncMethodError(t *testing.T) {
+	arg, err := Marshal(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = EvalFunc("inv", ModuleBase, arg, arg)
+	if !errors.Is(err, &JuliaError{Type: "MethodError"}) {
+		t.Fatal("expected MethodError, got", err)
+	}
+}
+
+func TestEvalFuncUndefined(t *testing.T) {
+	_, err := EvalFunc("__thisFunctionIsNotDefined", ModuleMain)
+	if !errors.Is(err, &JuliaError{Type: "UndefVarError"}) {
+		t.Fatal("expected UndefVarError, got", err)
+	}
+}
+
+func TestEvalSingularException(t *testing.T) {
+	_, err := Eval("using LinearAlgebra; inv([1.0 2.0; 2.0 4.0])")
+	if !errors.Is(err, &JuliaError{Type: "SingularException"}) {
+		t.Fatal("expected SingularException, got", err)
+	}
+}
@@ -25,7 +25,9 @@ marshal(x) = Vector{UInt8}(JSON2.write(x));
 )
 
 func main() {
-	julia.Initialize()
+	if err := julia.Initialize(); err != nil {
+		log.Fatal(err)
+	}
 	defer julia.Finalize()
 
 	// load julia code to marshal and unmarshal list of strings
@@ -8,7 +8,9 @@ import (
 )
 
 func main() {
-	julia.Initialize()
+	if err := julia.Initialize(); err != nil {
+		log.Fatal(err)
+	}
 	defer julia.Finalize()
 
 	// testing inv(5,5)
@@ -37,8 +39,8 @@ func main() {
 	}
 
 	// unmarshal response into matrix. it is important to unmarshal into
-	// types that match exactly those returned by julia, otherwise you will
-	// get segfaults
+	// types that match exactly those returned by julia, otherwise an error
+	// wrapping julia.ErrTypeMismatch is returned
 	if err := julia.Unmarshal(resp, mat); err != nil {
 		log.Fatal(err)
 	}
@@ -8,7 +8,9 @@ import (
 )
 
 func main() {
-	julia.Initialize()
+	if err := julia.Initialize(); err != nil {
+		log.Fatal(err)
+	}
 	defer julia.Finalize()
 
 	// marshal out a data type that can be passed to julia runtime
@@ -0,0 +1,131 @@
+package julia
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// executor runs functions on a single goroutine that is locked to its
+// OS thread. julia runtime is not thread safe and expects to be called
+// from the thread it was initialized on, whereas goroutines migrate
+// between OS threads, so all julia calls are funneled through executor.
+type executor struct {
+	requests chan *request
+
+	mu          sync.Mutex
+	thread      osThread
+	running     uint64
+	next        uint64
+	interrupted bool
+}
+
+// request is a function queued for execution along with its id, which is
+// used to interrupt it
+type request struct {
+	id   uint64
+	f    func() error
+	errC chan error
+}
+
+// newExecutor starts the executor goroutine
+func newExecutor() *executor {
+	e := &executor{
+		requests: make(chan *request),
+	}
+
+	started := make(chan struct{})
+	go e.run(started)
+	<-started
+
+	return e
+}
+
+// run executes requests in order. OS thread is never unlocked since
+// it is owned by julia runtime for the lifetime of the process
+func (e *executor) run(started chan<- struct{}) {
+	runtime.LockOSThread()
+
+	e.mu.Lock()
+	e.thread = currentThread()
+	e.mu.Unlock()
+	close(started)
+
+	for req := range e.requests {
+		e.mu.Lock()
+		e.running = req.id
+		e.mu.Unlock()
+
+		err := runSafely(req.f)
+
+		e.mu.Lock()
+		e.running = 0
+		interrupted := e.interrupted
+		e.interrupted = false
+		e.mu.Unlock()
+
+		req.errC <- err
+
+		// interrupts are acted on by julia asynchronously, hence one sent
+		// after the request completed is absorbed rather than thrown into
+		// the next request
+		if interrupted {
+			_ = runSafely(absorbInterrupt)
+		}
+	}
+}
+
+// runSafely runs f and returns its panic as an error, since the panic cannot
+// be recovered by the caller of do on the executor goroutine
+func runSafely(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic on julia executor thread: %v", r)
+		}
+	}()
+
+	return f()
+}
+
+// do runs f on the executor thread and waits for it to complete. f must
+// not call do itself, since that would deadlock the executor
+func (e *executor) do(f func() error) error {
+	_, errC, err := e.submit(context.Background(), f)
+	if err != nil {
+		return err
+	}
+
+	return <-errC
+}
+
+// submit queues f for execution and returns request id along with a
+// channel receiving the result. It returns an error if ctx is done
+// before the executor picks up the request
+func (e *executor) submit(ctx context.Context, f func() error) (uint64, <-chan error, error) {
+	e.mu.Lock()
+	e.next++
+	req := &request{
+		id:   e.next,
+		f:    f,
+		errC: make(chan error, 1),
+	}
+	e.mu.Unlock()
+
+	select {
+	case e.requests <- req:
+		return req.id, req.errC, nil
+	case <-ctx.Done():
+		return 0, nil, ctx.Err()
+	}
+}
+
+// interrupt interrupts julia code of the request, if it is still running
+func (e *executor) interrupt(id uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running == id && interruptThread(e.thread) {
+		e.interrupted = true
+	}
+}
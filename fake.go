@@ -0,0 +1,722 @@
+package julia
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FakeFunc computes the result of a scripted call from its arguments,
+// which are go values such as int64 or *Mat[float64]. Slices passed to
+// Marshal are received as one dimensional matrices, whereas structs and
+// maps are received as they are
+type FakeFunc func(args ...any) (any, error)
+
+// FakeBackend is an in-memory Backend for tests that run without julia.
+// Results of function calls and evaluations are scripted:
+//
+//	fake := julia.NewFakeBackend()
+//	fake.OnEvalFunc("inv", julia.ModuleBase, func(args ...any) (any, error) {
+//		return julia.NewMat([]float64{1, 0, 0, 1}, 2, 2)
+//	})
+//
+// This package links against libjulia by default, hence tests using the
+// fake without julia installed need to be built with julia_dlopen tag
+type FakeBackend struct {
+	mu    sync.Mutex
+	funcs map[fakeFuncKey]FakeFunc
+	evals map[string]FakeFunc
+	calls []string
+}
+
+type fakeFuncKey struct {
+	name       string
+	moduleType ModuleType
+}
+
+// fakeValue holds a go value on behalf of fake backend
+type fakeValue struct {
+	x any
+	b *FakeBackend
+}
+
+// NewFakeBackend creates a fake backend without any scripted results
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		funcs: make(map[fakeFuncKey]FakeFunc),
+		evals: make(map[string]FakeFunc),
+	}
+}
+
+// OnEvalFunc scripts the result of calling function by its name in the module
+func (f *FakeBackend) OnEvalFunc(name string, moduleType ModuleType, fn FakeFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.funcs[fakeFuncKey{name: name, moduleType: moduleType}] = fn
+}
+
+// OnEval scripts the result of evaluating input
+func (f *FakeBackend) OnEval(input string, fn FakeFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.evals[input] = fn
+}
+
+// Calls returns names of functions called so far in the order of calls
+func (f *FakeBackend) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.calls...)
+}
+
+func (f *FakeBackend) Eval(input string) (Handle, error) {
+	f.mu.Lock()
+	fn, ok := f.evals[input]
+	f.mu.Unlock()
+
+	if !ok {
+		return nil, &JuliaError{
+			Type:    "ErrorException",
+			Message: fmt.Sprintf("ErrorException: fake backend has no result scripted for %q", input),
+		}
+	}
+
+	return f.result(fn())
+}
+
+func (f *FakeBackend) EvalFunc(name string, moduleType ModuleType, args ...Handle) (Handle, error) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		value, err := f.value(arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value.x
+	}
+
+	f.mu.Lock()
+	fn, ok := f.funcs[fakeFuncKey{name: name, moduleType: moduleType}]
+	f.calls = append(f.calls, name)
+	f.mu.Unlock()
+
+	if !ok {
+		return nil, &JuliaError{
+			Type:    jlUndefVarErrType,
+			Message: fmt.Sprintf("%s: %s not defined", jlUndefVarErrType, name),
+		}
+	}
+
+	return f.result(fn(values...))
+}
+
+func (f *FakeBackend) Marshal(x any) (Handle, error) {
+	x, err := fakeCopy(x)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fakeValue{x: x, b: f}, nil
+}
+
+func (f *FakeBackend) Unmarshal(data Handle, x any) error {
+	value, err := f.value(data)
+	if err != nil {
+		return err
+	}
+
+	if isNilPointer(x) {
+		if value.x == nil {
+			return nil
+		}
+		return fmt.Errorf("%w: cannot unmarshal julia %s into nil %T", ErrTypeMismatch, value.Type(), x)
+	}
+
+	if m, ok := x.(interface{ copyFrom(src any) error }); ok {
+		return m.copyFrom(value.x)
+	}
+
+	if m, ok := value.x.(interface{ copyTo(dst any) bool }); ok && m.copyTo(x) {
+		return nil
+	}
+
+	// big numbers are unmarshaled into the pointer itself, whereas
+	// singletons have nothing to unmarshal
+	switch p := x.(type) {
+	case nil, *NothingType:
+		if value.x == nil {
+			return nil
+		}
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, value.Type(), x)
+	case *big.Int:
+		if src, ok := value.x.(*big.Int); ok {
+			p.Set(src)
+			return nil
+		}
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, value.Type(), x)
+	case *big.Float:
+		if src, ok := value.x.(*big.Float); ok {
+			p.Copy(src)
+			return nil
+		}
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, value.Type(), x)
+	}
+
+	target := reflect.ValueOf(x)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("invalid type, not supported %T", x)
+	}
+
+	switch x.(type) {
+	case *string, *[]string, *Symbol, *Char, *MissingType, *Int128, *UInt128:
+	default:
+		if isStructPointer(x) {
+			return f.unmarshalStruct(value, target.Elem())
+		}
+
+		if isMapPointer(x) {
+			return f.unmarshalMap(value, target.Elem())
+		}
+
+		if _, err := kindOf(deref(x)); err != nil {
+			return fmt.Errorf("invalid type, not supported %T", x)
+		}
+	}
+
+	if value.x == nil || target.Elem().Type() != reflect.TypeOf(value.x) {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, value.Type(), x)
+	}
+
+	// slices are copied the way unmarshaling from julia would
+	out, err := fakeCopy(value.x)
+	if err != nil {
+		return err
+	}
+
+	target.Elem().Set(reflect.ValueOf(out))
+	return nil
+}
+
+// unmarshalStruct populates fields of struct v from fields of go struct
+// held by value, matching them by julia names the way julia would
+func (f *FakeBackend) unmarshalStruct(value *fakeValue, v reflect.Value) error {
+	src := reflect.ValueOf(value.x)
+	if src.Kind() != reflect.Struct || src.NumField() == 0 {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %s", ErrTypeMismatch, value.Type(), v.Type())
+	}
+
+	indices := make(map[string]int)
+	for _, field := range structFields(src.Type()) {
+		indices[field.name] = field.index
+	}
+
+	for _, field := range structFields(v.Type()) {
+		i, ok := indices[field.name]
+		if !ok {
+			continue
+		}
+
+		elm, err := fakeCopy(fieldOf(src.Field(i)))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.name, err)
+		}
+
+		if err := f.unmarshalField(&fakeValue{x: elm, b: f}, v.Field(field.index)); err != nil {
+			return fmt.Errorf("field %s: %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalMap adds entries of go map held by value to map v the way
+// unmarshaling julia dict would
+func (f *FakeBackend) unmarshalMap(value *fakeValue, v reflect.Value) error {
+	src := reflect.ValueOf(value.x)
+	if src.Kind() != reflect.Map {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %s", ErrTypeMismatch, value.Type(), v.Type())
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	iter := src.MapRange()
+	for iter.Next() {
+		elm, err := fakeCopy(elementOf(iter.Key()))
+		if err != nil {
+			return fmt.Errorf("dict key %v: %w", iter.Key(), err)
+		}
+
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := f.unmarshalField(&fakeValue{x: elm, b: f}, key); err != nil {
+			return fmt.Errorf("dict key %v: %w", iter.Key(), err)
+		}
+
+		if elm, err = fakeCopy(elementOf(iter.Value())); err != nil {
+			return fmt.Errorf("dict value of key %v: %w", iter.Key(), err)
+		}
+
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := f.unmarshalField(&fakeValue{x: elm, b: f}, value); err != nil {
+			return fmt.Errorf("dict value of key %v: %w", iter.Key(), err)
+		}
+
+		v.SetMapIndex(key, value)
+	}
+
+	return nil
+}
+
+// unmarshalField unpacks value into struct field v the way unmarshaling
+// from julia would, i.e. pointers are set to nil for nothing
+func (f *FakeBackend) unmarshalField(value *fakeValue, v reflect.Value) error {
+	if v.Kind() != reflect.Ptr {
+		return f.Unmarshal(value, v.Addr().Interface())
+	}
+
+	if value.x == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	p := v
+	if p.IsNil() {
+		p = reflect.New(v.Type().Elem())
+	}
+
+	if err := f.Unmarshal(value, p.Interface()); err != nil {
+		return err
+	}
+
+	v.Set(p)
+	return nil
+}
+
+func (f *FakeBackend) TypeOf(data Handle) (string, error) {
+	value, err := f.value(data)
+	if err != nil {
+		return "", err
+	}
+
+	return value.Type(), nil
+}
+
+// result wraps output of a scripted function as a value
+func (f *FakeBackend) result(x any, err error) (Handle, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if x == nil {
+		return &fakeValue{b: f}, nil
+	}
+
+	return f.Marshal(x)
+}
+
+// value asserts handle to be a value of this fake backend
+func (f *FakeBackend) value(h Handle) (*fakeValue, error) {
+	value, ok := h.(*fakeValue)
+	if !ok || value == nil || value.b != f {
+		return nil, fmt.Errorf("invalid handle %T, not created by this fake backend", h)
+	}
+
+	return value, nil
+}
+
+// Type evaluates to julia representation of typeof the go value
+func (v *fakeValue) Type() string {
+	if v.x == nil {
+		return "Nothing"
+	}
+
+	if m, ok := v.x.(interface {
+		elem() any
+		rank() int
+	}); ok {
+		el := m.elem()
+		if _, ok := el.(bool); ok {
+			el = int8(0)
+		}
+
+		elType := fakeTypeName(el)
+		if _, ok := v.x.(maskedMat); ok {
+			elType = fmt.Sprintf("Union{Missing, %s}", elType)
+		}
+
+		switch n := m.rank(); n {
+		case 1:
+			return fmt.Sprintf("Vector{%s}", elType)
+		case 2:
+			return fmt.Sprintf("Matrix{%s}", elType)
+		default:
+			return fmt.Sprintf("Array{%s, %d}", elType, n)
+		}
+	}
+
+	return fakeTypeName(v.x)
+}
+
+// fakeTypeName returns julia type name of go primitive value
+func fakeTypeName(el any) string {
+	switch v := el.(type) {
+	case bool:
+		return "Bool"
+	case uint8:
+		return "UInt8"
+	case uint16:
+		return "UInt16"
+	case uint32:
+		return "UInt32"
+	case uint64:
+		return "UInt64"
+	case int8:
+		return "Int8"
+	case int16:
+		return "Int16"
+	case int32:
+		return "Int32"
+	case int64:
+		return "Int64"
+	case float32:
+		return "Float32"
+	case float64:
+		return "Float64"
+	case complex64:
+		return "ComplexF32"
+	case complex128:
+		return "ComplexF64"
+	case Int128:
+		return "Int128"
+	case UInt128:
+		return "UInt128"
+	case Float16:
+		return "Float16"
+	case Tuple:
+		names := make([]string, len(v))
+		for i := range v {
+			names[i] = (&fakeValue{x: v[i]}).Type()
+		}
+		return fmt.Sprintf("Tuple{%s}", strings.Join(names, ", "))
+	case Symbol:
+		return "Symbol"
+	case Char:
+		return "Char"
+	case MissingType:
+		return "Missing"
+	case *big.Int:
+		return "BigInt"
+	case *big.Float:
+		return "BigFloat"
+	case string:
+		return "String"
+	case []string:
+		return "Vector{String}"
+	default:
+		switch v := reflect.ValueOf(el); v.Kind() {
+		case reflect.Struct:
+			return fakeNamedTupleName(v)
+		case reflect.Map:
+			return fakeDictName(v)
+		}
+		return fmt.Sprintf("%T", el)
+	}
+}
+
+// fakeNamedTupleName returns julia type name of NamedTuple go struct v is
+// marshaled to, as printed by julia 1.10 or newer
+func fakeNamedTupleName(v reflect.Value) string {
+	var names []string
+	for _, field := range structFields(v.Type()) {
+		typeName := fmt.Sprintf("%T", v.Field(field.index).Interface())
+		if elm, err := fakeCopy(fieldOf(v.Field(field.index))); err == nil {
+			typeName = (&fakeValue{x: elm}).Type()
+		}
+		names = append(names, fmt.Sprintf("%s::%s", field.name, typeName))
+	}
+
+	return fmt.Sprintf("@NamedTuple{%s}", strings.Join(names, ", "))
+}
+
+// fakeDictName returns julia type name of Dict go map v is marshaled to
+func fakeDictName(v reflect.Value) string {
+	var keys, values []reflect.Value
+	iter := v.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key())
+		values = append(values, iter.Value())
+	}
+
+	return fmt.Sprintf("Dict{%s, %s}",
+		fakeElementName(v.Type().Key(), keys), fakeElementName(v.Type().Elem(), values))
+}
+
+// fakeElementName returns julia type name of dict keys or values the way
+// elementType determines it, except that differing types of elms are
+// joined as Any
+func fakeElementName(t reflect.Type, elms []reflect.Value) string {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Interface {
+			elms = []reflect.Value{reflect.Zero(t)}
+		}
+	case reflect.Struct:
+		if isScalarStruct(reflect.Zero(t)) {
+			elms = []reflect.Value{reflect.Zero(t)}
+		}
+	default:
+		elms = []reflect.Value{reflect.Zero(t)}
+	}
+
+	name := "Any"
+	for i, elm := range elms {
+		x, err := fakeCopy(elementOf(elm))
+		if err != nil {
+			return "Any"
+		}
+
+		if elmName := (&fakeValue{x: x}).Type(); i == 0 {
+			name = elmName
+		} else if elmName != name {
+			return "Any"
+		}
+	}
+
+	return name
+}
+
+// fakeCopy copies x the way marshaling to julia would, i.e. slices become
+// one dimensional matrices and matrices are copied
+func fakeCopy(x any) (any, error) {
+	switch v := x.(type) {
+	case nil, NothingType:
+		return nil, nil
+	case Symbol, Char, MissingType:
+		return v, nil
+	case bool, uint8, uint16, uint32, uint64, int8, int16, int32, int64, float32, float64, complex64, complex128, Int128, UInt128, Float16, string:
+		return v, nil
+	case []string:
+		return append([]string(nil), v...), nil
+	case []bool:
+		return copyOf(&Mat[bool]{elms: v, dims: []int{len(v)}}), nil
+	case []uint8:
+		return copyOf(&Mat[uint8]{elms: v, dims: []int{len(v)}}), nil
+	case []uint16:
+		return copyOf(&Mat[uint16]{elms: v, dims: []int{len(v)}}), nil
+	case []uint32:
+		return copyOf(&Mat[uint32]{elms: v, dims: []int{len(v)}}), nil
+	case []uint64:
+		return copyOf(&Mat[uint64]{elms: v, dims: []int{len(v)}}), nil
+	case []int8:
+		return copyOf(&Mat[int8]{elms: v, dims: []int{len(v)}}), nil
+	case []int16:
+		return copyOf(&Mat[int16]{elms: v, dims: []int{len(v)}}), nil
+	case []int32:
+		return copyOf(&Mat[int32]{elms: v, dims: []int{len(v)}}), nil
+	case []int64:
+		return copyOf(&Mat[int64]{elms: v, dims: []int{len(v)}}), nil
+	case []float32:
+		return copyOf(&Mat[float32]{elms: v, dims: []int{len(v)}}), nil
+	case []float64:
+		return copyOf(&Mat[float64]{elms: v, dims: []int{len(v)}}), nil
+	case []complex64:
+		return copyOf(&Mat[complex64]{elms: v, dims: []int{len(v)}}), nil
+	case []complex128:
+		return copyOf(&Mat[complex128]{elms: v, dims: []int{len(v)}}), nil
+	case []Int128:
+		return copyOf(&Mat[Int128]{elms: v, dims: []int{len(v)}}), nil
+	case []UInt128:
+		return copyOf(&Mat[UInt128]{elms: v, dims: []int{len(v)}}), nil
+	case []Float16:
+		return copyOf(&Mat[Float16]{elms: v, dims: []int{len(v)}}), nil
+	case *big.Int:
+		if v == nil {
+			return nil, nil
+		}
+		return new(big.Int).Set(v), nil
+	case *big.Float:
+		if v == nil {
+			return nil, nil
+		}
+		return new(big.Float).Copy(v), nil
+	case *Mat[bool]:
+		return copyOf(v), nil
+	case *Mat[uint8]:
+		return copyOf(v), nil
+	case *Mat[uint16]:
+		return copyOf(v), nil
+	case *Mat[uint32]:
+		return copyOf(v), nil
+	case *Mat[uint64]:
+		return copyOf(v), nil
+	case *Mat[int8]:
+		return copyOf(v), nil
+	case *Mat[int16]:
+		return copyOf(v), nil
+	case *Mat[int32]:
+		return copyOf(v), nil
+	case *Mat[int64]:
+		return copyOf(v), nil
+	case *Mat[float32]:
+		return copyOf(v), nil
+	case *Mat[float64]:
+		return copyOf(v), nil
+	case *Mat[complex64]:
+		return copyOf(v), nil
+	case *Mat[complex128]:
+		return copyOf(v), nil
+	case *Mat[Int128]:
+		return copyOf(v), nil
+	case *Mat[UInt128]:
+		return copyOf(v), nil
+	case *Mat[Float16]:
+		return copyOf(v), nil
+	case interface{ clone() any }:
+		return v.clone(), nil
+	case []any:
+		return fakeCopy(Tuple(v))
+	case Tuple:
+		elms := make(Tuple, len(v))
+		for i := range v {
+			elm, err := fakeCopy(v[i])
+			if err != nil {
+				return nil, err
+			}
+			elms[i] = elm
+		}
+		return elms, nil
+	default:
+		// structs and maps are held by value, whose fields and entries
+		// are copied when unmarshaled
+		if s, ok := structOf(v); ok {
+			return s.Interface(), nil
+		}
+		if m, ok := mapOf(v); ok {
+			return m.Interface(), nil
+		}
+		return nil, fmt.Errorf("invalid type, not supported %T", v)
+	}
+}
+
+// copyOf returns a deep copy of matrix
+func copyOf[T PrimitiveTypes](v *Mat[T]) *Mat[T] {
+	return &Mat[T]{
+		elms: append([]T(nil), v.elms...),
+		dims: append([]int(nil), v.dims...),
+	}
+}
+
+// copyFrom copies src matrix into g, populating an empty matrix or
+// checking shape of a preallocated one
+func (g *Mat[T]) copyFrom(src any) error {
+	m, ok := src.(*Mat[T])
+	if !ok {
+		return fmt.Errorf("%w: cannot unmarshal %T into %T", ErrTypeMismatch, src, g)
+	}
+
+	if len(g.dims) == 0 {
+		g.dims = append([]int(nil), m.dims...)
+		g.elms = append([]T(nil), m.elms...)
+		return nil
+	}
+
+	if len(g.dims) != len(m.dims) {
+		return fmt.Errorf("%w: cannot unmarshal %T of rank %d into mat of rank %d",
+			ErrTypeMismatch, src, len(m.dims), len(g.dims))
+	}
+
+	if !equalDims(g.dims, m.dims) {
+		return fmt.Errorf("%w: cannot unmarshal julia array of dims %v into mat of dims %v",
+			ErrShapeMismatch, m.dims, g.dims)
+	}
+
+	copy(g.elms, m.elms)
+	return nil
+}
+
+// elem returns zero value of matrix element type
+func (g *Mat[T]) elem() any {
+	var el T
+	return el
+}
+
+// rank returns number of matrix dimensions
+func (g *Mat[T]) rank() int {
+	return len(g.dims)
+}
+
+// clone returns a deep copy of masked matrix
+func (g *MaskedMat[T]) clone() any {
+	return &MaskedMat[T]{
+		elms:  append([]T(nil), g.elms...),
+		valid: append([]bool(nil), g.valid...),
+		dims:  append([]int(nil), g.dims...),
+	}
+}
+
+// copyFrom copies src masked matrix into g the way copyFrom of Mat does.
+// a matrix without missing values is copied as if all elements were valid
+func (g *MaskedMat[T]) copyFrom(src any) error {
+	m, ok := src.(*MaskedMat[T])
+	if !ok {
+		mat, ok := src.(*Mat[T])
+		if !ok {
+			return fmt.Errorf("%w: cannot unmarshal %T into %T", ErrTypeMismatch, src, g)
+		}
+
+		m = &MaskedMat[T]{elms: mat.elms, valid: make([]bool, len(mat.elms)), dims: mat.dims}
+		for i := range m.valid {
+			m.valid[i] = true
+		}
+	}
+
+	elms := &Mat[T]{elms: g.elms, dims: g.dims}
+	if err := elms.copyFrom(&Mat[T]{elms: m.elms, dims: m.dims}); err != nil {
+		return err
+	}
+
+	valid := &Mat[bool]{elms: g.valid, dims: g.dims}
+	if err := valid.copyFrom(&Mat[bool]{elms: m.valid, dims: m.dims}); err != nil {
+		return err
+	}
+
+	g.elms, g.valid, g.dims = elms.elms, valid.elms, elms.dims
+	return nil
+}
+
+// copyTo copies vector with missing values into dst if it is a pointer
+// to slice of pointers of matching element type
+func (g *MaskedMat[T]) copyTo(dst any) bool {
+	p, ok := dst.(*[]*T)
+	if !ok || len(g.dims) != 1 {
+		return false
+	}
+
+	m := g.clone().(*MaskedMat[T])
+	out := make([]*T, len(m.elms))
+	for i := range out {
+		if m.valid[i] {
+			out[i] = &m.elms[i]
+		}
+	}
+
+	*p = out
+	return true
+}
+
+// elem returns zero value of masked matrix element type
+func (g *MaskedMat[T]) elem() any {
+	var el T
+	return el
+}
+
+// rank returns number of masked matrix dimensions
+func (g *MaskedMat[T]) rank() int {
+	return len(g.dims)
+}
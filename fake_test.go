@@ -0,0 +1,267 @@
+package julia
+
+import (
+	"errors"
+	"testing"
+)
+
+// invert is an example of code depending on this package via Backend
+func invert(b Backend, m *Mat[float64]) (*Mat[float64], error) {
+	arg, err := b.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.EvalFunc("inv", ModuleBase, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	out := new(Mat[float64])
+	if err := b.Unmarshal(resp, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func TestFakeBackendScriptedFunc(t *testing.T) {
+	fake := NewFakeBackend()
+	fake.OnEvalFunc("inv", ModuleBase, func(args ...any) (any, error) {
+		m, ok := args[0].(*Mat[float64])
+		if !ok {
+			t.Fatalf("expected *Mat[float64] argument, got %T", args[0])
+		}
+
+		return NewMat([]float64{1 / m.GetElms()[0], 0, 0, 1 / m.GetElms()[3]}, 2, 2)
+	})
+
+	x, err := NewMat([]float64{4, 0, 0, 2}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y, err := invert(fake, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalDims(y.GetDims(), []int{2, 2}) || y.GetElms()[0] != 0.25 || y.GetElms()[3] != 0.5 {
+		t.Fatal("did not receive expected values", y.GetDims(), y.GetElms())
+	}
+
+	if calls := fake.Calls(); len(calls) != 1 || calls[0] != "inv" {
+		t.Fatal("expected a call to inv, got", calls)
+	}
+}
+
+func TestFakeBackendTypes(t *testing.T) {
+	fake := NewFakeBackend()
+
+	arg, err := fake.Marshal([]int64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName, _ := fake.TypeOf(arg); typeName != "Vector{Int64}" {
+		t.Fatal("expected Vector{Int64}, got", typeName)
+	}
+
+	arg, err = fake.Marshal(float64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int64
+	if err := fake.Unmarshal(arg, &n); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	var f float64
+	if err := fake.Unmarshal(arg, &f); err != nil || f != 1 {
+		t.Fatal("expected 1, got", f, err)
+	}
+}
+
+func TestFakeBackendStrings(t *testing.T) {
+	fake := NewFakeBackend()
+
+	arg, err := fake.Marshal([]string{"abcd", "12345678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName, _ := fake.TypeOf(arg); typeName != "Vector{String}" {
+		t.Fatal("expected Vector{String}, got", typeName)
+	}
+
+	var list []string
+	if err := fake.Unmarshal(arg, &list); err != nil || len(list) != 2 || list[1] != "12345678" {
+		t.Fatal("did not receive expected values", list, err)
+	}
+
+	var s string
+	if err := fake.Unmarshal(arg, &s); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestFakeBackendUndefined(t *testing.T) {
+	fake := NewFakeBackend()
+
+	if _, err := fake.EvalFunc("inv", ModuleBase); !errors.Is(err, &JuliaError{Type: "UndefVarError"}) {
+		t.Fatal("expected UndefVarError, got", err)
+	}
+}
+
+func TestFakeBackendNothing(t *testing.T) {
+	fake := NewFakeBackend()
+
+	arg, err := fake.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName, _ := fake.TypeOf(arg); typeName != "Nothing" {
+		t.Fatal("expected Nothing, got", typeName)
+	}
+
+	if err := fake.Unmarshal(arg, &Nothing); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.Unmarshal(arg, (*float64)(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err = fake.Marshal(Symbol("U"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var name Symbol
+	if err := fake.Unmarshal(arg, &name); err != nil || name != "U" {
+		t.Fatal("expected U, got", name, err)
+	}
+
+	if err := fake.Unmarshal(arg, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	if err := fake.Unmarshal(arg, (*Mat[float64])(nil)); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch unmarshaling into nil *Mat[float64], got", err)
+	}
+}
+
+func TestFakeBackendMaskedMat(t *testing.T) {
+	fake := NewFakeBackend()
+
+	mat, err := NewMaskedMat([]float64{1, 0, 3}, []bool{true, false, true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := fake.Marshal(mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName, _ := fake.TypeOf(arg); typeName != "Vector{Union{Missing, Float64}}" {
+		t.Fatal("expected Vector{Union{Missing, Float64}}, got", typeName)
+	}
+
+	out := new(MaskedMat[float64])
+	if err := fake.Unmarshal(arg, out); err != nil || !out.IsMissing(1) || out.GetElms()[2] != 3 {
+		t.Fatal("did not receive expected values", out.GetElms(), out.GetValid(), err)
+	}
+
+	var x []*float64
+	if err := fake.Unmarshal(arg, &x); err != nil || len(x) != 3 || x[1] != nil || *x[2] != 3 {
+		t.Fatal("did not receive expected values", x, err)
+	}
+}
+
+func TestFakeBackendTuple(t *testing.T) {
+	fake := NewFakeBackend()
+
+	arg, err := fake.Marshal([]any{int64(1), "abcd", nil, Tuple{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName, _ := fake.TypeOf(arg); typeName != "Tuple{Int64, String, Nothing, Tuple{}}" {
+		t.Fatal("expected Tuple{Int64, String, Nothing, Tuple{}}, got", typeName)
+	}
+}
+
+func TestFakeBackendStruct(t *testing.T) {
+	fake := NewFakeBackend()
+
+	mat, err := NewMat([]float64{1, 2, 3, 4}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type params struct {
+		Alpha float64       `julia:"alpha"`
+		Mat   *Mat[float64] `julia:"mat"`
+		Scale *float64      `julia:"scale"`
+	}
+
+	arg, err := fake.Marshal(params{Alpha: 2, Mat: mat})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName, _ := fake.TypeOf(arg); typeName != "@NamedTuple{alpha::Float64, mat::Matrix{Float64}, scale::Nothing}" {
+		t.Fatal("expected @NamedTuple{alpha::Float64, mat::Matrix{Float64}, scale::Nothing}, got", typeName)
+	}
+
+	var out struct {
+		Alpha float64      `julia:"alpha"`
+		Mat   Mat[float64] `julia:"mat"`
+		Scale *float64     `julia:"scale"`
+	}
+	out.Scale = new(float64)
+	if err := fake.Unmarshal(arg, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Alpha != 2 || !equalDims(out.Mat.GetDims(), []int{2, 2}) || out.Scale != nil {
+		t.Fatal("did not receive expected values", out)
+	}
+
+	var n int64
+	if err := fake.Unmarshal(arg, &struct {
+		Alpha *int64 `julia:"alpha"`
+	}{Alpha: &n}); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestFakeBackendMap(t *testing.T) {
+	fake := NewFakeBackend()
+
+	arg, err := fake.Marshal(map[string][]float64{"a": {1, 2}, "b": {3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName, _ := fake.TypeOf(arg); typeName != "Dict{String, Vector{Float64}}" {
+		t.Fatal("expected Dict{String, Vector{Float64}}, got", typeName)
+	}
+
+	var out map[string]*Mat[float64]
+	if err := fake.Unmarshal(arg, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 || !equalDims(out["a"].GetDims(), []int{2}) || out["b"].GetElms()[0] != 3 {
+		t.Fatal("did not receive expected values", out)
+	}
+
+	var wrong map[string]float64
+	if err := fake.Unmarshal(arg, &wrong); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
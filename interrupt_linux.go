@@ -0,0 +1,78 @@
+package julia
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// osThread identifies OS thread julia runtime runs on by its thread id
+type osThread = int
+
+// currentThread returns OS thread of the caller
+func currentThread() osThread {
+	return syscall.Gettid()
+}
+
+// interruptThread interrupts julia code running on the thread julia was
+// initialized on. julia blocks SIGINT on its threads and waits for it on
+// its signal listener thread, which throws InterruptException at the next
+// safepoint of the running julia code, whereas SIGINT sent to the julia
+// thread itself remains pending. The listener cannot be told apart from
+// other julia threads, hence SIGINT is sent to all of them, whereas go
+// threads are skipped, since go runtime would terminate the process.
+// It returns true if SIGINT was sent
+func interruptThread(thread osThread) bool {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return false
+	}
+
+	pid := os.Getpid()
+	sent := false
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil || tid == thread || !isJuliaThread(tid) {
+			continue
+		}
+
+		if syscall.Tgkill(pid, tid, syscall.SIGINT) == nil {
+			sent = true
+		}
+	}
+
+	return sent
+}
+
+// isJuliaThread checks signal mask of the thread, which blocks SIGINT for
+// threads created by julia. SIGSEGV is never blocked by julia, since it
+// implements safepoints, whereas go blocks all signals while creating
+// threads
+func isJuliaThread(tid int) bool {
+	status, err := os.ReadFile(fmt.Sprintf("/proc/self/task/%d/status", tid))
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		if !strings.HasPrefix(line, "SigBlk:") {
+			continue
+		}
+
+		mask, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "SigBlk:")), 16, 64)
+		if err != nil {
+			return false
+		}
+
+		return mask&sigMask(syscall.SIGINT) != 0 && mask&sigMask(syscall.SIGSEGV) == 0
+	}
+
+	return false
+}
+
+// sigMask returns bit of signal sig in signal masks listed by /proc
+func sigMask(sig syscall.Signal) uint64 {
+	return 1 << (uint(sig) - 1)
+}
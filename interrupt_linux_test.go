@@ -0,0 +1,21 @@
+package julia
+
+import (
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+func TestInterruptSkipsGoThreads(t *testing.T) {
+	if mask := sigMask(syscall.SIGINT); mask != 0x2 {
+		t.Fatalf("expected %#x for SIGINT, got %#x", 0x2, mask)
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	// go threads do not block SIGINT, which would terminate the process
+	if isJuliaThread(syscall.Gettid()) {
+		t.Fatal("expected go thread not to be taken for julia thread")
+	}
+}
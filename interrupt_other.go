@@ -0,0 +1,20 @@
+//go:build !linux
+
+package julia
+
+// osThread identifies OS thread julia runtime runs on. Threads are not
+// told apart, since julia code is not interrupted
+type osThread = struct{}
+
+// currentThread returns OS thread of the caller
+func currentThread() osThread {
+	return osThread{}
+}
+
+// interruptThread does not interrupt julia code, which is supported on
+// linux only, see interrupt_linux.go. julia blocks SIGINT on its threads
+// and waits for it on its signal listener thread, which cannot be found
+// otherwise. It returns false, since nothing was sent
+func interruptThread(thread osThread) bool {
+	return false
+}
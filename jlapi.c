@@ -0,0 +1,68 @@
+// jlapi.c resolves julia C API from libjulia at runtime when built with
+// julia_dlopen tag, see jlapi.h
+#include "jlapi.h"
+
+int gojl_memory_arrays;
+
+#ifdef JULIA_DLOPEN
+
+#include <dlfcn.h>
+#include <stdio.h>
+
+gojl_api_t gojl_api;
+
+static void *gojl_lib;
+static char gojl_err[512];
+
+// gojl_sym looks up symbol in libjulia. julia releases before 1.9 export
+// jl_init and jl_init_with_image with __threading suffix only
+static void *gojl_sym(void *lib, const char *name)
+{
+	void *sym = dlsym(lib, name);
+	if (sym != NULL) {
+		return sym;
+	}
+
+	char alt[128];
+	snprintf(alt, sizeof(alt), "%s__threading", name);
+	return dlsym(lib, alt);
+}
+
+const char *gojl_load(const char *path)
+{
+	if (gojl_lib != NULL) {
+		return NULL;
+	}
+
+	void *lib = dlopen(path, RTLD_NOW | RTLD_GLOBAL);
+	if (lib == NULL) {
+		snprintf(gojl_err, sizeof(gojl_err), "%s", dlerror());
+		return gojl_err;
+	}
+
+	gojl_api_t api;
+
+#define GOJL_RESOLVE(field, name) \
+	if ((*(void **)&api.field = gojl_sym(lib, name)) == NULL) { \
+		snprintf(gojl_err, sizeof(gojl_err), "%s: undefined symbol %s", path, name); \
+		dlclose(lib); \
+		return gojl_err; \
+	}
+#define GOJL_RESOLVE_FUNC(ret, name, params, args) GOJL_RESOLVE(name, #name)
+#define GOJL_RESOLVE_VOID_FUNC(name, params, args) GOJL_RESOLVE(name, #name)
+#define GOJL_RESOLVE_GLOBAL(type, name) GOJL_RESOLVE(jl_##name, "jl_" #name)
+	GOJL_FUNCS(GOJL_RESOLVE_FUNC)
+	GOJL_VOID_FUNCS(GOJL_RESOLVE_VOID_FUNC)
+	GOJL_GLOBALS(GOJL_RESOLVE_GLOBAL)
+#undef GOJL_RESOLVE_GLOBAL
+#undef GOJL_RESOLVE_VOID_FUNC
+#undef GOJL_RESOLVE_FUNC
+#undef GOJL_RESOLVE
+
+	gojl_api = api;
+	gojl_lib = lib;
+
+	return NULL;
+}
+
+#endif
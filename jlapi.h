@@ -0,0 +1,288 @@
+// jlapi.h declares the subset of julia C API used by this package.
+//
+// By default the package links against libjulia and this header forwards
+// to julia.h. When built with julia_dlopen tag, JULIA_DLOPEN is defined
+// and the same API is resolved from libjulia via dlopen/dlsym when the
+// runtime is initialized, so that binaries neither need julia headers
+// to build nor libjulia to start.
+//
+// julia globals, such as modules and data types, cannot be accessed via
+// cgo unless they are plain C variables, hence they are read through
+// gojl_ accessor functions in both modes.
+#ifndef GOJL_API_H
+#define GOJL_API_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+// GOJL_GLOBALS lists julia globals used by this package as (type, name)
+#define GOJL_GLOBALS(G) \
+	G(jl_module_t, main_module) \
+	G(jl_module_t, base_module) \
+	G(jl_datatype_t, bool_type) \
+	G(jl_datatype_t, uint8_type) \
+	G(jl_datatype_t, uint16_type) \
+	G(jl_datatype_t, uint32_type) \
+	G(jl_datatype_t, uint64_type) \
+	G(jl_datatype_t, int8_type) \
+	G(jl_datatype_t, int16_type) \
+	G(jl_datatype_t, int32_type) \
+	G(jl_datatype_t, int64_type) \
+	G(jl_datatype_t, float32_type) \
+	G(jl_datatype_t, float64_type) \
+	G(jl_datatype_t, float16_type) \
+	G(jl_datatype_t, string_type) \
+	G(jl_datatype_t, symbol_type) \
+	G(jl_datatype_t, char_type) \
+	G(jl_datatype_t, datatype_type) \
+	G(jl_datatype_t, anytuple_type) \
+	G(jl_value_t, array_type) \
+	G(jl_value_t, nothing)
+
+#ifndef JULIA_DLOPEN
+
+#include <julia.h>
+
+#define GOJL_GLOBAL_ACCESSOR(type, name) \
+	static inline type *gojl_##name(void) { return jl_##name; }
+GOJL_GLOBALS(GOJL_GLOBAL_ACCESSOR)
+
+// jl_typeof and jl_is_array are macros, which cannot be called via cgo
+static inline jl_value_t *gojl_typeof(jl_value_t *v) { return jl_typeof(v); }
+static inline int gojl_is_array(jl_value_t *v) { return jl_is_array(v); }
+
+// array rank and element type are read via julia.h macros, which are
+// available in all supported julia versions unlike their C API functions
+static inline int gojl_array_rank(jl_value_t *a) { return jl_array_ndims(a); }
+static inline jl_value_t *gojl_array_eltype(jl_value_t *a) { return jl_tparam0(jl_typeof(a)); }
+
+// jl_symbol_name is a macro in some julia versions
+static inline const char *gojl_symbol_name(jl_value_t *s) { return jl_symbol_name((jl_sym_t *)s); }
+static inline jl_value_t *gojl_field_types(jl_value_t *t) { return (jl_value_t *)jl_get_fieldtypes((jl_datatype_t *)t); }
+static inline jl_datatype_t *gojl_tuple_type(jl_value_t **p, size_t n) { return (jl_datatype_t *)jl_apply_tuple_type_v(p, n); }
+
+// names, modules and flags of data types are read from jl_datatype_t via
+// julia.h, whose fields and macros are available in all supported versions
+static inline const char *gojl_type_name(jl_value_t *t) { return jl_symbol_name(((jl_datatype_t *)t)->name->name); }
+static inline const char *gojl_type_module(jl_value_t *t) { return jl_symbol_name(((jl_datatype_t *)t)->name->module->name); }
+static inline int gojl_is_bits_type(jl_value_t *t) { return jl_isbits(t); }
+static inline int gojl_is_mutable_type(jl_value_t *t) { return jl_is_mutable(t); }
+static inline int gojl_is_abstract_type(jl_value_t *t) { return jl_is_abstracttype(t); }
+static inline int gojl_is_concrete_type(jl_value_t *t) { return jl_is_concrete_type(t); }
+
+#else
+
+typedef struct _jl_value_t jl_value_t;
+typedef struct _jl_value_t jl_function_t;
+typedef struct _jl_sym_t jl_sym_t;
+typedef struct _jl_module_t jl_module_t;
+typedef struct _jl_datatype_t jl_datatype_t;
+typedef struct _jl_array_t jl_array_t;
+
+// GOJL_FUNCS lists julia functions used by this package as
+// (return type, name, parameters, arguments)
+#define GOJL_FUNCS(F) \
+	F(jl_value_t *, jl_eval_string, (const char *str), (str)) \
+	F(jl_value_t *, jl_exception_occurred, (void), ()) \
+	F(jl_value_t *, jl_call, (jl_function_t *f, jl_value_t **args, int32_t nargs), (f, args, nargs)) \
+	F(jl_value_t *, jl_call0, (jl_function_t *f), (f)) \
+	F(jl_value_t *, jl_call1, (jl_function_t *f, jl_value_t *a), (f, a)) \
+	F(jl_sym_t *, jl_symbol, (const char *str), (str)) \
+	F(jl_value_t *, jl_get_global, (jl_module_t *m, jl_sym_t *var), (m, var)) \
+	F(jl_value_t *, jl_typeof, (jl_value_t *v), (v)) \
+	F(const char *, jl_typeof_str, (jl_value_t *v), (v)) \
+	F(int, jl_isa, (jl_value_t *a, jl_value_t *t), (a, t)) \
+	F(int, jl_types_equal, (jl_value_t *a, jl_value_t *b), (a, b)) \
+	F(const char *, jl_string_ptr, (jl_value_t *s), (s)) \
+	F(jl_value_t *, jl_cstr_to_string, (const char *str), (str)) \
+	F(jl_value_t *, jl_pchar_to_string, (const char *str, size_t len), (str, len)) \
+	F(const char *, jl_symbol_name, (jl_sym_t *s), (s)) \
+	F(jl_value_t *, jl_get_field, (jl_value_t *o, const char *fld), (o, fld)) \
+	F(jl_value_t *, jl_get_fieldtypes, (jl_value_t *st), (st)) \
+	F(jl_value_t *, jl_get_nth_field, (jl_value_t *v, size_t i), (v, i)) \
+	F(jl_value_t *, jl_apply_tuple_type_v, (jl_value_t **p, size_t np), (p, np)) \
+	F(jl_value_t *, jl_new_structv, (jl_datatype_t *type, jl_value_t **args, uint32_t na), (type, args, na)) \
+	F(jl_value_t *, jl_box_bool, (int8_t x), (x)) \
+	F(jl_value_t *, jl_box_uint8, (uint8_t x), (x)) \
+	F(jl_value_t *, jl_box_uint16, (uint16_t x), (x)) \
+	F(jl_value_t *, jl_box_uint32, (uint32_t x), (x)) \
+	F(jl_value_t *, jl_box_uint64, (uint64_t x), (x)) \
+	F(jl_value_t *, jl_box_int8, (int8_t x), (x)) \
+	F(jl_value_t *, jl_box_int16, (int16_t x), (x)) \
+	F(jl_value_t *, jl_box_int32, (int32_t x), (x)) \
+	F(jl_value_t *, jl_box_int64, (int64_t x), (x)) \
+	F(jl_value_t *, jl_box_float32, (float x), (x)) \
+	F(jl_value_t *, jl_box_float64, (double x), (x)) \
+	F(jl_value_t *, jl_box_char, (uint32_t x), (x)) \
+	F(jl_value_t *, jl_new_bits, (jl_value_t *bt, const void *src), (bt, src)) \
+	F(int8_t, jl_unbox_bool, (jl_value_t *v), (v)) \
+	F(uint8_t, jl_unbox_uint8, (jl_value_t *v), (v)) \
+	F(uint16_t, jl_unbox_uint16, (jl_value_t *v), (v)) \
+	F(uint32_t, jl_unbox_uint32, (jl_value_t *v), (v)) \
+	F(uint64_t, jl_unbox_uint64, (jl_value_t *v), (v)) \
+	F(int8_t, jl_unbox_int8, (jl_value_t *v), (v)) \
+	F(int16_t, jl_unbox_int16, (jl_value_t *v), (v)) \
+	F(int32_t, jl_unbox_int32, (jl_value_t *v), (v)) \
+	F(int64_t, jl_unbox_int64, (jl_value_t *v), (v)) \
+	F(float, jl_unbox_float32, (jl_value_t *v), (v)) \
+	F(double, jl_unbox_float64, (jl_value_t *v), (v)) \
+	F(jl_value_t *, jl_apply_array_type, (jl_value_t *type, size_t dim), (type, dim)) \
+	F(jl_array_t *, jl_alloc_array_1d, (jl_value_t *atype, size_t nr), (atype, nr)) \
+	F(jl_array_t *, jl_alloc_array_2d, (jl_value_t *atype, size_t nr, size_t nc), (atype, nr, nc)) \
+	F(jl_array_t *, jl_alloc_array_3d, (jl_value_t *atype, size_t nr, size_t nc, size_t z), (atype, nr, nc, z)) \
+	F(int, jl_ver_major, (void), ()) \
+	F(int, jl_ver_minor, (void), ()) \
+	F(int, jl_ver_patch, (void), ()) \
+	F(const char *, jl_ver_string, (void), ())
+
+// GOJL_VOID_FUNCS lists julia functions without return value as
+// (name, parameters, arguments)
+#define GOJL_VOID_FUNCS(V) \
+	V(jl_init, (void), ()) \
+	V(jl_init_with_image, (const char *julia_bindir, const char *image_path), (julia_bindir, image_path)) \
+	V(jl_atexit_hook, (int status), (status)) \
+	V(jl_parse_opts, (int *argcp, char ***argvp), (argcp, argvp)) \
+	V(jl_exception_clear, (void), ()) \
+	V(jl_exit_on_sigint, (int on), (on)) \
+	V(jl_gc_queue_root, (const jl_value_t *root), (root))
+
+// gojl_api holds julia symbols resolved by gojl_load
+typedef struct {
+#define GOJL_FUNC_FIELD(ret, name, params, args) ret (*name) params;
+#define GOJL_VOID_FUNC_FIELD(name, params, args) void (*name) params;
+#define GOJL_GLOBAL_FIELD(type, name) type **jl_##name;
+	GOJL_FUNCS(GOJL_FUNC_FIELD)
+	GOJL_VOID_FUNCS(GOJL_VOID_FUNC_FIELD)
+	GOJL_GLOBALS(GOJL_GLOBAL_FIELD)
+#undef GOJL_FUNC_FIELD
+#undef GOJL_VOID_FUNC_FIELD
+#undef GOJL_GLOBAL_FIELD
+} gojl_api_t;
+
+extern gojl_api_t gojl_api;
+
+// gojl_load opens libjulia at path and resolves julia symbols, returning
+// NULL on success or an error message otherwise
+const char *gojl_load(const char *path);
+
+#define GOJL_FUNC_WRAPPER(ret, name, params, args) \
+	static inline ret name params { return gojl_api.name args; }
+#define GOJL_VOID_FUNC_WRAPPER(name, params, args) \
+	static inline void name params { gojl_api.name args; }
+#define GOJL_GLOBAL_ACCESSOR(type, name) \
+	static inline type *gojl_##name(void) { return *gojl_api.jl_##name; }
+GOJL_FUNCS(GOJL_FUNC_WRAPPER)
+GOJL_VOID_FUNCS(GOJL_VOID_FUNC_WRAPPER)
+GOJL_GLOBALS(GOJL_GLOBAL_ACCESSOR)
+
+// jl_get_function is an inline function of julia.h
+static inline jl_function_t *jl_get_function(jl_module_t *m, const char *name)
+{
+	return (jl_function_t *)jl_get_global(m, jl_symbol(name));
+}
+
+static inline jl_value_t *gojl_typeof(jl_value_t *v) { return jl_typeof(v); }
+static inline int gojl_is_array(jl_value_t *v) { return jl_isa(v, gojl_array_type()); }
+
+// array rank and element type are read via julia functions since
+// layout of array types differs between julia versions
+static inline int gojl_array_rank(jl_value_t *a)
+{
+	return (int)jl_unbox_int64(jl_call1(jl_get_function(gojl_base_module(), "ndims"), a));
+}
+
+static inline jl_value_t *gojl_array_eltype(jl_value_t *a)
+{
+	return jl_call1(jl_get_function(gojl_base_module(), "eltype"), a);
+}
+
+static inline const char *gojl_symbol_name(jl_value_t *s) { return jl_symbol_name((jl_sym_t *)s); }
+static inline jl_value_t *gojl_field_types(jl_value_t *t) { return jl_get_fieldtypes(t); }
+static inline jl_datatype_t *gojl_tuple_type(jl_value_t **p, size_t n) { return (jl_datatype_t *)jl_apply_tuple_type_v(p, n); }
+
+// names, modules and flags of data types are read via julia functions
+// since layout of jl_datatype_t differs between julia versions
+static inline jl_value_t *gojl_call_base(const char *name, jl_value_t *x)
+{
+	return jl_call1(jl_get_function(gojl_base_module(), name), x);
+}
+
+static inline const char *gojl_type_name(jl_value_t *t) { return jl_symbol_name((jl_sym_t *)gojl_call_base("nameof", t)); }
+static inline const char *gojl_type_module(jl_value_t *t) { return jl_symbol_name((jl_sym_t *)gojl_call_base("nameof", gojl_call_base("parentmodule", t))); }
+static inline int gojl_is_bits_type(jl_value_t *t) { return jl_unbox_bool(gojl_call_base("isbitstype", t)); }
+static inline int gojl_is_mutable_type(jl_value_t *t) { return jl_unbox_bool(gojl_call_base("ismutabletype", t)); }
+static inline int gojl_is_abstract_type(jl_value_t *t) { return jl_unbox_bool(gojl_call_base("isabstracttype", t)); }
+static inline int gojl_is_concrete_type(jl_value_t *t) { return jl_unbox_bool(gojl_call_base("isconcretetype", t)); }
+
+#endif
+
+// gojl_memory_arrays is set when the runtime starts with julia 1.11 or
+// newer, whose arrays are backed by Memory{T}
+extern int gojl_memory_arrays;
+
+// gojl_array_data returns pointer to array elements, which is the first
+// field of jl_array_t in all supported julia versions, i.e. data before
+// 1.11 and ref.ptr_or_offset since then. Elements of arrays of primitive
+// types are stored inline, hence ptr_or_offset is always a pointer
+static inline void *gojl_array_data(jl_value_t *a) { return *(void **)a; }
+
+// gojl_array_ptr_ref returns element i of an array of boxed values, such
+// as Vector{String}, whose elements are stored as pointers. NULL is
+// returned for undefined elements
+static inline jl_value_t *gojl_array_ptr_ref(jl_value_t *a, size_t i) { return ((jl_value_t **)gojl_array_data(a))[i]; }
+
+#ifndef JULIA_DLOPEN
+
+// gojl_array_ptr_set stores x, which may be NULL, as element i of an array
+// of boxed values, such as Vector{Any}, followed by the write barrier
+static inline void gojl_array_ptr_set(jl_value_t *a, size_t i, jl_value_t *x) { jl_array_ptr_set(a, i, x); }
+
+#else
+
+// gojl_array_ptr_set stores x, which may be NULL, as element i of an array
+// of boxed values, such as Vector{Any}. It is followed by the write barrier
+// of jl_gc_wb, which queues an old object pointing to a young one, i.e.
+// gc bits of their tags are 3 and 0 or 2 respectively. Elements are owned
+// by the Memory object of the array since julia 1.11, whereas arrays that
+// are not views, such as those this package stores into, own them before
+static inline void gojl_array_ptr_set(jl_value_t *a, size_t i, jl_value_t *x)
+{
+	__atomic_store_n(&((jl_value_t **)gojl_array_data(a))[i], x, __ATOMIC_RELEASE);
+	if (x == NULL) {
+		return;
+	}
+
+	jl_value_t *owner = gojl_memory_arrays ? ((jl_value_t **)a)[1] : a;
+	if ((((uintptr_t *)owner)[-1] & 3) == 3 && (((uintptr_t *)x)[-1] & 1) == 0) {
+		jl_gc_queue_root(owner);
+	}
+}
+
+#endif
+
+// gojl_string_len returns length of julia String in bytes, which is stored
+// before its data. jl_string_len is a macro in julia.h
+static inline size_t gojl_string_len(jl_value_t *s) { return *(size_t *)s; }
+
+// gojl_svec_len and gojl_svec_ref access simple vectors, such as type
+// parameters, whose layout, i.e. length followed by elements, is the same
+// in all supported julia versions
+static inline size_t gojl_svec_len(jl_value_t *t) { return *(size_t *)t; }
+static inline jl_value_t *gojl_svec_ref(jl_value_t *t, size_t i) { return ((jl_value_t **)t)[1 + i]; }
+
+static inline int gojl_is_datatype(jl_value_t *v) { return jl_isa(v, (jl_value_t *)gojl_datatype_type()); }
+static inline int gojl_is_tuple(jl_value_t *v) { return jl_isa(v, (jl_value_t *)gojl_anytuple_type()); }
+
+// gojl_array_dim returns size of array along dimension i. dims follow
+// data, length and flags fields before julia 1.11, whereas they follow
+// the memory reference, i.e. pointer and memory fields, since then
+static inline size_t gojl_array_dim(jl_value_t *a, int i)
+{
+	if (gojl_memory_arrays) {
+		return ((size_t *)a)[2 + i];
+	}
+
+	return ((size_t *)a)[3 + i];
+}
+
+#endif
@@ -19,9 +19,6 @@ serialize(x) = Vector{UInt8}(JSON2.write(x))
 // should work for arbitrary data types including structs,
 // maps, slices etc.
 func TestJsonSerializationSendToJulia(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	if _, err := Eval(preload); err != nil {
 		t.Fatal(err)
 	}
@@ -46,9 +43,6 @@ func TestJsonSerializationSendToJulia(t *testing.T) {
 }
 
 func TestJsonSerializationReceiveFromJulia(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	if _, err := Eval(preload); err != nil {
 		t.Fatal(err)
 	}
@@ -58,12 +52,7 @@ func TestJsonSerializationReceiveFromJulia(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	n := Len(resp)
-	mat, err := NewMat(make([]byte, n))
-	if err != nil {
-		t.Fatal(err)
-	}
-
+	mat := new(Mat[byte])
 	if err := Unmarshal(resp, mat); err != nil {
 		t.Fatal(err)
 	}
@@ -1,17 +1,23 @@
 package julia
 
 /*
-// Start with the basic example from https://docs.julialang.org/en/v1/manual/embedding/
-//
-// Obviously the paths below may need to be modified to match your julia install location and version number.
-//
-#cgo CFLAGS: -fPIC -DJULIA_INIT_DIR="/usr/local/julia/lib" -I/usr/local/julia/include/julia -I.
-#cgo LDFLAGS: -L/usr/local/julia/lib/julia  -L/usr/local/julia/lib -Wl,-rpath,/usr/local/julia/lib -ljulia
-#include <julia.h>
+// julia C API is declared by jlapi.h, whereas libjulia is either linked,
+// see linked.go, or loaded at runtime, see dlopen.go
+#cgo CFLAGS: -fPIC -I.
+#include <stdlib.h>
+#include "jlapi.h"
+
+// julia keeps references to parsed command line arguments, so argv is
+// allocated in C memory and is never freed
+static char **gojl_argv(int argc) { return (char **)calloc(argc + 1, sizeof(char *)); }
+static void gojl_argv_set(char **argv, int i, char *arg) { argv[i] = arg; }
 */
 import "C"
 import (
+	"context"
 	"fmt"
+	"math/big"
+	"reflect"
 	"unsafe"
 )
 
@@ -23,18 +29,203 @@ const (
 )
 
 const (
-	jlValueTypeOf = "__jlValueTypeOf"
+	jlFieldNames      = "__jlFieldNames"
+	jlCatch           = "__jlCatch"
+	jlShowError       = "__jlShowError"
+	jlShowBacktrace   = "__jlShowBacktrace"
+	jlLastException   = "__jlLastException"
+	jlLastBacktrace   = "__jlLastBacktrace"
+	jlEvalString      = "__jlEvalString"
+	jlNewArray        = "__jlNewArray"
+	jlRoots           = "__jlRoots"
+	jlGrowRoots       = "__jlGrowRoots"
+	jlField           = "__jlField"
+	jlIndex           = "__jlIndex"
+	jlSize            = "__jlSize"
+	jlBigFloat        = "__jlBigFloat"
+	jlMasked          = "__jlMasked"
+	jlCoalesce        = "__jlCoalesce"
+	jlValid           = "__jlValid"
+	jlNamedTuple      = "__jlNamedTuple"
+	jlDict            = "__jlDict"
+	jlPairs           = "__jlPairs"
+	jlAbsorbInterrupt = "__jlAbsorbInterrupt"
+	jlUndefVarErrType = "UndefVarError"
 )
 
-func Initialize() {
+// jlPreamble declares a few functions for use in this library.
+// __jlCatch invokes a function and records the exception and its backtrace
+// before rethrowing, since backtrace is no longer available once the
+// exception has propagated to the C API. Values referenced by go are kept
+// in slots of __jlRoots, which are set from C, since passing isbits values
+// to julia functions would store new boxes rather than those go refers to.
+// Arrays with missing values are built and
+// split on julia side, since their memory layout depends on julia version.
+// map is used instead of broadcasting, which returns BitArray for Bool.
+// Keys and values of dicts are collected as Vector{Any}, whose elements
+// are boxed. Interrupts are thrown at a safepoint, such as yield, hence
+// yielding absorbs an interrupt that arrived after the call completed.
+var jlPreamble = fmt.Sprintf(`
+%[1]s(T) = Core.svec(map(Symbol, fieldnames(T))...)
+%[7]s(code) = Core.eval(Main, Meta.parseall(code))
+%[8]s(T, dims) = T(undef, Tuple(dims))
+const %[9]s = Any[]
+%[10]s(n) = (resize!(%[9]s, n); nothing)
+%[5]s = nothing
+%[6]s = nothing
+function %[2]s(f, args...)
+    try
+        return f(args...)
+    catch e
+        global %[5]s = e
+        global %[6]s = catch_backtrace()
+        rethrow()
+    end
+end
+%[3]s() = sprint(showerror, %[5]s)
+%[4]s() = %[6]s === nothing ? "" : sprint(Base.show_backtrace, %[6]s)
+%[11]s(x, name) = getproperty(x, Symbol(name))
+%[12]s(x, i) = x[i...]
+%[13]s(x) = Int64[size(x)...]
+%[14]s(s, precision) = BigFloat(s, precision=precision)
+function %[15]s(x, valid)
+    y = Array{Union{Missing,eltype(x)}}(x)
+    y[valid .== 0] .= missing
+    return y
+end
+%[16]s(x) = map(v -> coalesce(v, zero(nonmissingtype(eltype(x)))), x)
+%[17]s(x) = map(!ismissing, x)
+%[18]s(names, values) = NamedTuple{names}(values)
+%[19]s(K, V, n) = sizehint!(Dict{K,V}(), n)
+%[20]s(d) = (collect(Any, keys(d)), collect(Any, values(d)))
+%[21]s() = try yield() catch e; e isa InterruptException || rethrow() end
+`,
+	jlFieldNames,
+	jlCatch,
+	jlShowError,
+	jlShowBacktrace,
+	jlLastException,
+	jlLastBacktrace,
+	jlEvalString,
+	jlNewArray,
+	jlRoots,
+	jlGrowRoots,
+	jlField,
+	jlIndex,
+	jlSize,
+	jlBigFloat,
+	jlMasked,
+	jlCoalesce,
+	jlValid,
+	jlNamedTuple,
+	jlDict,
+	jlPairs,
+	jlAbsorbInterrupt,
+)
+
+// Initialize initializes julia runtime via the default runtime. Calling it
+// more than once is a no-op. Use New to initialize julia runtime
+// with custom options
+func Initialize() error {
+	return current().Initialize()
+}
+
+// Finalize finalizes the default runtime, after which julia runtime
+// cannot be used or initialized again in this process
+func Finalize() error {
+	return current().Finalize()
+}
+
+// Version returns version of julia runtime the package level functions
+// forward to
+func Version() (string, error) {
+	return current().Version()
+}
+
+// initialize applies options, sets up julia context and declares
+// functions used by this library
+func initialize(opts *Options) error {
+	if err := loadLibrary(opts); err != nil {
+		return err
+	}
+
+	if err := detectVersion(); err != nil {
+		return err
+	}
+
+	args, err := opts.args()
+	if err != nil {
+		return err
+	}
+
+	binDir, image, err := opts.image()
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		argc := C.int(len(args) + 1)
+		argv := C.gojl_argv(argc)
+		C.gojl_argv_set(argv, 0, C.CString("julia"))
+		for i, arg := range args {
+			C.gojl_argv_set(argv, C.int(i+1), C.CString(arg))
+		}
+
+		C.jl_parse_opts(&argc, &argv)
+	}
+
 	/* required: setup the Julia context */
-	C.jl_init()
+	if len(binDir) > 0 {
+		cBinDir := C.CString(binDir)
+		defer C.free(unsafe.Pointer(cBinDir))
+
+		var cImage *C.char
+		if len(image) > 0 {
+			cImage = C.CString(image)
+			defer C.free(unsafe.Pointer(cImage))
+		}
+
+		C.jl_init_with_image(cBinDir, cImage)
+	} else {
+		C.jl_init()
+	}
+
+	// julia cannot be initialized again from here on, process.mu is held
+	// by Runtime.Initialize
+	process.started = true
 
-	// declare a few functions for use in this library
-	_, _ = Eval(fmt.Sprintf("%s(x) = Vector{UInt8}(string(typeof(x)))", jlValueTypeOf))
+	// interrupts throw InterruptException rather than exiting the process
+	C.jl_exit_on_sigint(0)
+
+	preamble := C.CString(jlPreamble)
+	defer C.free(unsafe.Pointer(preamble))
+	C.jl_eval_string(preamble)
+
+	return exception()
 }
 
-func Finalize() {
+// detectVersion checks that julia version is supported and selects
+// array layout matching it
+func detectVersion() error {
+	major, minor := int(C.jl_ver_major()), int(C.jl_ver_minor())
+	if major != 1 || minor < 7 {
+		return fmt.Errorf("unsupported julia version %s, 1.7 or newer is required", version())
+	}
+
+	if minor >= 11 {
+		C.gojl_memory_arrays = 1
+	}
+
+	return nil
+}
+
+// version returns julia version string
+func version() string {
+	return C.GoString(C.jl_ver_string())
+}
+
+// finalize notifies julia runtime that the program is about to terminate
+func finalize() {
 	/* strongly recommended: notify Julia that the
 	   program is about to terminate. this allows
 	   Julia time to cleanup pending write requests
@@ -43,67 +234,326 @@ func Finalize() {
 	C.jl_atexit_hook(0)
 }
 
-// jlValue represents generic data type to pass to/from Julia runtime.
-// Users are not expected to instantiate this struct and an instance
-// of it is typically accessed via Marshal/Unmarshal functions.
-type jlValue struct {
-	value *C.jl_value_t
+func Marshal[T PrimitiveTypes | ScalarTypes | PrimitiveSliceTypes | MatTypes | MaskedMatTypes | BigTypes | TupleTypes](x T) (*Value, error) {
+	return current().Marshal(x)
+}
+
+func Unmarshal[T PrimitivePointerTypes | MatTypes | MaskedMatTypes | PointerSliceTypes | BigTypes](data *Value, x T) error {
+	return data.runtime().Unmarshal(data, x)
+}
+
+// UnmarshalTuple unpacks elements of julia tuple into x, such as index and
+// value returned by findmax. Each of x is a target supported by Unmarshal
+func UnmarshalTuple(data *Value, x ...any) error {
+	return data.runtime().UnmarshalTuple(data, x...)
+}
+
+// MarshalStruct packs exported fields of struct x, or of the struct x
+// points to, into julia NamedTuple, whose names are set by `julia:"name"`
+// field tags. Marshal cannot accept structs, since its type constraints
+// cannot express arbitrary struct types
+func MarshalStruct(x any) (*Value, error) {
+	if _, ok := structOf(x); !ok {
+		return nil, fmt.Errorf("invalid type, not supported %T", x)
+	}
+
+	return current().Marshal(x)
+}
+
+// UnmarshalStruct populates exported fields of the struct x points to from
+// fields of julia NamedTuple or any other julia struct matched by names
+func UnmarshalStruct(data *Value, x any) error {
+	if !isStructPointer(x) {
+		return fmt.Errorf("invalid type, not supported %T", x)
+	}
+
+	return data.runtime().Unmarshal(data, x)
+}
+
+// MarshalMap packs entries of map x, or of the map x points to, into
+// julia Dict, whose key and value types are those go key and value types
+// are marshaled to. Marshal cannot accept maps for the same reason it
+// cannot accept structs, see MarshalStruct
+func MarshalMap(x any) (*Value, error) {
+	if _, ok := mapOf(x); !ok {
+		return nil, fmt.Errorf("invalid type, not supported %T", x)
+	}
+
+	return current().Marshal(x)
+}
+
+// UnmarshalMap adds pairs of julia Dict, or any other AbstractDict, to the
+// map x points to, which is allocated if it is nil
+func UnmarshalMap(data *Value, x any) error {
+	if !isMapPointer(x) {
+		return fmt.Errorf("invalid type, not supported %T", x)
+	}
+
+	return data.runtime().Unmarshal(data, x)
+}
+
+// Eval evaluates input as if it were julia code. An exception thrown
+// by julia runtime is returned as *JuliaError
+func Eval(input string) (*Value, error) {
+	return current().Eval(input)
+}
+
+// EvalFunc evaluates a function literal, represented by name and module it is defined in,
+// and passes any optional arguments to it, returning any output from julia runtime.
+// An exception thrown by julia runtime is returned as *JuliaError
+func EvalFunc(name string, moduleType ModuleType, args ...*Value) (*Value, error) {
+	return current().EvalFunc(name, moduleType, args...)
+}
+
+// EvalContext is like Eval, however, cancellation of ctx interrupts
+// julia code. See Runtime.EvalContext
+func EvalContext(ctx context.Context, input string) (*Value, error) {
+	return current().EvalContext(ctx, input)
 }
 
-// Type evaluates to julia representation of typeof
-func (g *jlValue) Type() string {
-	resp, _ := EvalFunc(jlValueTypeOf, ModuleMain, g)
-	n := Len(resp)
+// EvalFuncContext is like EvalFunc, however, cancellation of ctx interrupts
+// julia code. See Runtime.EvalContext
+func EvalFuncContext(ctx context.Context, name string, moduleType ModuleType, args ...*Value) (*Value, error) {
+	return current().EvalFuncContext(ctx, name, moduleType, args...)
+}
 
-	out, _ := NewMat(make([]uint8, n))
-	_ = Unmarshal(resp, out)
+// typeOf returns julia representation of typeof
+func typeOf(g *Value) string {
+	if err := check(g); err != nil {
+		return ""
+	}
 
-	return string(out.GetElms())
+	return repr(C.gojl_typeof(g.value))
 }
 
-func Len(g *jlValue) int {
-	length, _ := EvalFunc("length", ModuleBase, g)
+// length returns julia length of the value
+func length(g *Value) int {
+	resp, err := evalFunc("length", ModuleBase, g)
+	if err != nil {
+		return 0
+	}
 
 	var n int64
-	_ = Unmarshal(length, &n)
+	_ = unmarshal(resp, &n)
 
 	return int(n)
 }
 
-func Marshal[T PrimitiveTypes | PrimitiveSliceTypes | MatTypes](x T) (*jlValue, error) {
-	return marshal(x)
-}
+// eval evaluates input as julia code
+func eval(input string) (*Value, error) {
+	code := C.CString(input)
+	defer C.free(unsafe.Pointer(code))
 
-func Unmarshal[T PrimitivePointerTypes | MatTypes](data *jlValue, x T) error {
-	return unmarshal(data, x)
+	evalString, err := getFunction(jlEvalString, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	return call(evalString, C.jl_cstr_to_string(code))
 }
 
-// Eval evaluates input as if it were julia code
-func Eval(input string) (*jlValue, error) {
-	return &jlValue{value: C.jl_eval_string(C.CString(input))}, nil
+// evalFunc calls a function by its name in the module passing args to it
+func evalFunc(name string, moduleType ModuleType, args ...*Value) (*Value, error) {
+	f, err := getFunction(name, moduleType)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]*C.jl_value_t, len(args))
+	for i, arg := range args {
+		if arg != nil && arg.released {
+			return nil, ErrReleased
+		}
+		inputs[i] = arg.value
+	}
+
+	return call(f, inputs...)
 }
 
-// EvalFunc evaluates a function literal, represented by name and module it is defined in,
-// and passes any optional arguments to it, returning any output from julia runtime
-func EvalFunc(name string, moduleType ModuleType, args ...*jlValue) (*jlValue, error) {
+// getFunction looks up a function by its name in the module
+func getFunction(name string, moduleType ModuleType) (*C.jl_function_t, error) {
+	fName := C.CString(name)
+	defer C.free(unsafe.Pointer(fName))
+
 	var f *C.jl_function_t
 	switch moduleType {
 	case ModuleBase:
-		f = C.jl_get_function(C.jl_base_module, C.CString(name))
+		f = C.jl_get_function(C.gojl_base_module(), fName)
 	case ModuleMain:
-		f = C.jl_get_function(C.jl_main_module, C.CString(name))
+		f = C.jl_get_function(C.gojl_main_module(), fName)
+	default:
+		return nil, fmt.Errorf("invalid module type %d", moduleType)
 	}
 
-	inputs := make([]*C.jl_value_t, len(args))
-	for i, arg := range args {
-		inputs[i] = arg.value
+	if f == nil {
+		return nil, &JuliaError{
+			Type:    jlUndefVarErrType,
+			Message: fmt.Sprintf("%s: %s not defined", jlUndefVarErrType, name),
+		}
 	}
 
-	if len(args) > 0 {
-		return &jlValue{value: C.jl_call(f, &(inputs[0]), C.int(len(inputs)))}, nil
-	} else {
-		return &jlValue{value: C.jl_call0(f)}, nil
+	return f, nil
+}
+
+// call invokes function f via __jlCatch so that any exception is
+// recorded along with its backtrace and returned as *JuliaError
+func call(f *C.jl_function_t, args ...*C.jl_value_t) (*Value, error) {
+	catcher, err := getFunction(jlCatch, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]*C.jl_value_t, 0, len(args)+1)
+	inputs = append(inputs, f)
+	inputs = append(inputs, args...)
+
+	value := C.jl_call(catcher, &(inputs[0]), C.int(len(inputs)))
+	if err := exception(); err != nil {
+		return nil, err
+	}
+
+	return &Value{value: value}, nil
+}
+
+// roots tracks slots of __jlRoots, which holds values referenced by go.
+// Slots of released values are reused. It is accessed on the executor
+// thread only
+var roots struct {
+	vector *C.jl_value_t
+	len    int
+	free   []int
+}
+
+// root keeps value referenced by julia so that julia garbage collector
+// does not free it while it is used by go. The value is stored in a slot
+// of __jlRoots by its pointer, which keeps the same julia object go
+// refers to, including boxes of isbits values
+func root(g *Value) error {
+	if g.rooted {
+		return nil
+	}
+
+	if len(roots.free) == 0 {
+		if err := growRoots(); err != nil {
+			return err
+		}
+	}
+
+	n := len(roots.free) - 1
+	g.slot, roots.free = roots.free[n], roots.free[:n]
+	g.rooted = true
+
+	C.gojl_array_ptr_set(roots.vector, C.size_t(g.slot), g.value)
+	return nil
+}
+
+// growRoots doubles the number of slots of __jlRoots, adding new slots to
+// the free list
+func growRoots() error {
+	if roots.vector == nil {
+		vector, err := getFunction(jlRoots, ModuleMain)
+		if err != nil {
+			return err
+		}
+		roots.vector = vector
+	}
+
+	f, err := getFunction(jlGrowRoots, ModuleMain)
+	if err != nil {
+		return err
+	}
+
+	n := 2 * roots.len
+	if n == 0 {
+		n = 64
 	}
+
+	if _, err := call(f, C.jl_box_int64(C.long(n))); err != nil {
+		return err
+	}
+
+	for i := n - 1; i >= roots.len; i-- {
+		roots.free = append(roots.free, i)
+	}
+	roots.len = n
+
+	return nil
+}
+
+// release drops reference to value taken by root
+func release(g *Value) error {
+	if g.released {
+		return nil
+	}
+
+	if g.rooted {
+		C.gojl_array_ptr_set(roots.vector, C.size_t(g.slot), nil)
+		roots.free = append(roots.free, g.slot)
+		g.rooted = false
+	}
+
+	g.released = true
+	return nil
+}
+
+// rooted calls f while value is rooted, such as isbits fields boxed by
+// jl_get_nth_field, which are not referenced by julia otherwise
+func rooted(value *C.jl_value_t, f func() error) error {
+	// rooted via a separate value, since release marks it released
+	tmp := &Value{value: value}
+	if err := root(tmp); err != nil {
+		return err
+	}
+	defer func() { _ = release(tmp) }()
+
+	return f()
+}
+
+// absorbInterrupt catches InterruptException pending from an interrupt
+// that julia acted on after the interrupted call had completed
+func absorbInterrupt() error {
+	f, err := getFunction(jlAbsorbInterrupt, ModuleMain)
+	if err != nil {
+		return err
+	}
+
+	_, err = call(f)
+	return err
+}
+
+// exception checks if julia runtime has a pending exception, in which case
+// it is cleared and returned as *JuliaError
+func exception() error {
+	exc := C.jl_exception_occurred()
+	if exc == nil {
+		return nil
+	}
+	C.jl_exception_clear()
+
+	jErr := &JuliaError{
+		Type: C.GoString(C.jl_typeof_str(exc)),
+	}
+
+	// message and backtrace are formatted on julia side from the values
+	// recorded by __jlCatch. These calls should not throw, however,
+	// failing to format them should not hide the original exception
+	if f, err := getFunction(jlShowError, ModuleMain); err == nil {
+		if msg := C.jl_call0(f); C.jl_exception_occurred() == nil {
+			jErr.Message = C.GoString(C.jl_string_ptr(msg))
+		} else {
+			C.jl_exception_clear()
+		}
+	}
+
+	if f, err := getFunction(jlShowBacktrace, ModuleMain); err == nil {
+		if bt := C.jl_call0(f); C.jl_exception_occurred() == nil {
+			jErr.Backtrace = C.GoString(C.jl_string_ptr(bt))
+		} else {
+			C.jl_exception_clear()
+		}
+	}
+
+	return jErr
 }
 
 // https://discourse.julialang.org/t/problems-scaling-jl-alloc-array-2d-c-api/63341
@@ -131,115 +581,196 @@ func allocArray(arrayType *C.jl_value_t, dims ...int) (*C.jl_array_t, error) {
 			C.ulong(dims[2]),
 		)
 	default:
-		jdims := make([]C.ulong, n)
+		// arrays of higher rank are allocated on julia side, which
+		// requires dims as a tuple. dims are passed as a vector and
+		// converted to a tuple by __jlNewArray
+		dimsType, err := getArrayType(1, int64(0))
+		if err != nil {
+			return nil, err
+		}
+
+		jdims := C.jl_alloc_array_1d(dimsType, C.ulong(n))
+		ptr := C.gojl_array_data((*C.jl_value_t)(unsafe.Pointer(jdims)))
 		for i := range dims {
-			jdims[i] = C.ulong(uint64(dims[i]))
+			*(*int64)(unsafe.Pointer(uintptr(ptr) + uintptr(i)*8)) = int64(dims[i])
 		}
 
-		jdimPtr := (*(C.jl_value_t))(unsafe.Pointer(&jdims[0]))
+		f, err := getFunction(jlNewArray, ModuleMain)
+		if err != nil {
+			return nil, err
+		}
 
-		array = C.jl_new_array(arrayType, jdimPtr)
+		value, err := call(f, arrayType, (*C.jl_value_t)(unsafe.Pointer(jdims)))
+		if err != nil {
+			return nil, err
+		}
+
+		array = (*C.jl_array_t)(unsafe.Pointer(value.value))
 	}
 
 	return array, nil
 }
 
-// marshal packs supported input to a generic jlValue type to pass to
+// marshal packs supported input to a generic Value type to pass to
 // julia runtime
-func marshal(x any) (*jlValue, error) {
+func marshal(x any) (*Value, error) {
 	switch v := x.(type) {
+	case nil:
+		return &Value{value: C.gojl_nothing()}, nil
+	case NothingType:
+		return &Value{value: C.gojl_nothing()}, nil
+	case MissingType:
+		return getMissing()
+	case Symbol:
+		return &Value{value: newSymbol(v)}, nil
+	case Char:
+		return &Value{value: C.jl_box_char(C.uint32_t(v.encode()))}, nil
 	case bool:
 		if v {
-			return &jlValue{value: C.jl_box_bool(C.schar(int8(1)))}, nil
+			return &Value{value: C.jl_box_bool(C.schar(int8(1)))}, nil
 		} else {
-			return &jlValue{value: C.jl_box_bool(C.schar(int8(0)))}, nil
+			return &Value{value: C.jl_box_bool(C.schar(int8(0)))}, nil
 		}
 	case uint8:
-		return &jlValue{value: C.jl_box_uint8(C.uchar(v))}, nil
+		return &Value{value: C.jl_box_uint8(C.uchar(v))}, nil
 	case uint16:
-		return &jlValue{value: C.jl_box_uint16(C.ushort(v))}, nil
+		return &Value{value: C.jl_box_uint16(C.ushort(v))}, nil
 	case uint32:
-		return &jlValue{value: C.jl_box_uint32(C.uint(v))}, nil
+		return &Value{value: C.jl_box_uint32(C.uint(v))}, nil
 	case uint64:
-		return &jlValue{value: C.jl_box_uint64(C.ulong(v))}, nil
+		return &Value{value: C.jl_box_uint64(C.ulong(v))}, nil
 	case int8:
-		return &jlValue{value: C.jl_box_int8(C.schar(v))}, nil
+		return &Value{value: C.jl_box_int8(C.schar(v))}, nil
 	case int16:
-		return &jlValue{value: C.jl_box_int16(C.short(v))}, nil
+		return &Value{value: C.jl_box_int16(C.short(v))}, nil
 	case int32:
-		return &jlValue{value: C.jl_box_int32(C.int(v))}, nil
+		return &Value{value: C.jl_box_int32(C.int(v))}, nil
 	case int64:
-		return &jlValue{value: C.jl_box_int64(C.long(v))}, nil
+		return &Value{value: C.jl_box_int64(C.long(v))}, nil
 	case float32:
-		return &jlValue{value: C.jl_box_float32(C.float(v))}, nil
+		return &Value{value: C.jl_box_float32(C.float(v))}, nil
 	case float64:
-		return &jlValue{value: C.jl_box_float64(C.double(v))}, nil
+		return &Value{value: C.jl_box_float64(C.double(v))}, nil
+	case complex64:
+		return newBits(v)
+	case complex128:
+		return newBits(v)
+	case Int128:
+		return newBits(v)
+	case UInt128:
+		return newBits(v)
+	case Float16:
+		return newBits(v)
+	case *big.Int:
+		if v == nil {
+			return marshal(nil)
+		}
+		return marshalBigInt(v)
+	case *big.Float:
+		if v == nil {
+			return marshal(nil)
+		}
+		return marshalBigFloat(v)
+	case string:
+		return &Value{value: newString(v)}, nil
+	case []string:
+		return marshalStrings(v)
 	case []bool:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []uint8:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []uint16:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []uint32:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []uint64:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []float32:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []float64:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
+	case []complex64:
+		m, err := NewMat(v, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return marshal(m)
+	case []complex128:
+		m, err := NewMat(v, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return marshal(m)
+	case []Int128:
+		m, err := NewMat(v, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return marshal(m)
+	case []UInt128:
+		m, err := NewMat(v, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return marshal(m)
+	case []Float16:
+		m, err := NewMat(v, len(v))
+		if err != nil {
+			return nil, err
+		}
+		return marshal(m)
 	case []int8:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []int16:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []int32:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case []int64:
 		m, err := NewMat(v, len(v))
 		if err != nil {
 			return nil, err
 		}
-		return Marshal(m)
+		return marshal(m)
 	case *Mat[bool]:
 		return marshalMat[bool, *bool](v)
 	case *Mat[uint8]:
@@ -262,15 +793,51 @@ func marshal(x any) (*jlValue, error) {
 		return marshalMat[float32, *float32](v)
 	case *Mat[float64]:
 		return marshalMat[float64, *float64](v)
+	case *Mat[complex64]:
+		return marshalMat[complex64, *complex64](v)
+	case *Mat[complex128]:
+		return marshalMat[complex128, *complex128](v)
+	case *Mat[Int128]:
+		return marshalMat[Int128, *Int128](v)
+	case *Mat[UInt128]:
+		return marshalMat[UInt128, *UInt128](v)
+	case *Mat[Float16]:
+		return marshalMat[Float16, *Float16](v)
+	case maskedMat:
+		return v.marshal()
+	case Tuple:
+		return marshalTuple(v)
+	case []any:
+		return marshalTuple(v)
 	default:
+		if s, ok := structOf(v); ok {
+			return marshalStruct(s)
+		}
+		if m, ok := mapOf(v); ok {
+			return marshalMap(m)
+		}
 		return nil, fmt.Errorf("invalid type, not supported %T", v)
 	}
 }
 
-// unmarshal unpacks generic jlValue and populates pointer value in x
-func unmarshal(data *jlValue, x any) error {
+// unmarshal unpacks generic Value and populates pointer value in x
+func unmarshal(data *Value, x any) error {
+	if err := checkType(data, x); err != nil {
+		return err
+	}
+
+	if isNilPointer(x) {
+		return nil
+	}
+
 	value := data.value
 	switch v := x.(type) {
+	case nil, *NothingType, *MissingType:
+		// nothing to unpack from singletons
+	case *Symbol:
+		*v = Symbol(C.GoString(C.gojl_symbol_name(value)))
+	case *Char:
+		*v = decodeChar(uint32(*(*C.uint32_t)(unsafe.Pointer(value))))
 	case *bool:
 		if C.jl_unbox_bool(value) == 1 {
 			*v = true
@@ -297,29 +864,99 @@ func unmarshal(data *jlValue, x any) error {
 		*v = float32(C.jl_unbox_float32(value))
 	case *float64:
 		*v = float64(C.jl_unbox_float64(value))
+	case *complex64:
+		// there is no unboxing function for complex numbers, however,
+		// boxed values point to their data
+		*v = *(*complex64)(unsafe.Pointer(value))
+	case *complex128:
+		*v = *(*complex128)(unsafe.Pointer(value))
+	case *Int128:
+		*v = *(*Int128)(unsafe.Pointer(value))
+	case *UInt128:
+		*v = *(*UInt128)(unsafe.Pointer(value))
+	case *Float16:
+		*v = *(*Float16)(unsafe.Pointer(value))
+	case *big.Int:
+		return unmarshalBigInt(data, v)
+	case *big.Float:
+		return unmarshalBigFloat(data, v)
+	case *string:
+		*v = goString(value)
+	case *[]string:
+		return unmarshalStrings(data, v)
 	case *Mat[bool]:
-		unmarshalMat[bool, *bool](data, v)
+		return unmarshalMat[bool, *bool](data, v)
 	case *Mat[uint8]:
-		unmarshalMat[uint8, *uint8](data, v)
+		return unmarshalMat[uint8, *uint8](data, v)
 	case *Mat[uint16]:
-		unmarshalMat[uint16, *uint16](data, v)
+		return unmarshalMat[uint16, *uint16](data, v)
 	case *Mat[uint32]:
-		unmarshalMat[uint32, *uint32](data, v)
+		return unmarshalMat[uint32, *uint32](data, v)
 	case *Mat[uint64]:
-		unmarshalMat[uint64, *uint64](data, v)
+		return unmarshalMat[uint64, *uint64](data, v)
 	case *Mat[int8]:
-		unmarshalMat[int8, *int8](data, v)
+		return unmarshalMat[int8, *int8](data, v)
 	case *Mat[int16]:
-		unmarshalMat[int16, *int16](data, v)
+		return unmarshalMat[int16, *int16](data, v)
 	case *Mat[int32]:
-		unmarshalMat[int32, *int32](data, v)
+		return unmarshalMat[int32, *int32](data, v)
 	case *Mat[int64]:
-		unmarshalMat[int64, *int64](data, v)
+		return unmarshalMat[int64, *int64](data, v)
 	case *Mat[float32]:
-		unmarshalMat[float32, *float32](data, v)
+		return unmarshalMat[float32, *float32](data, v)
 	case *Mat[float64]:
-		unmarshalMat[float64, *float64](data, v)
+		return unmarshalMat[float64, *float64](data, v)
+	case *Mat[complex64]:
+		return unmarshalMat[complex64, *complex64](data, v)
+	case *Mat[complex128]:
+		return unmarshalMat[complex128, *complex128](data, v)
+	case *Mat[Int128]:
+		return unmarshalMat[Int128, *Int128](data, v)
+	case *Mat[UInt128]:
+		return unmarshalMat[UInt128, *UInt128](data, v)
+	case *Mat[Float16]:
+		return unmarshalMat[Float16, *Float16](data, v)
+	case maskedMat:
+		return v.unmarshal(data)
+	case *[]*bool:
+		return unmarshalPointers(data, v)
+	case *[]*uint8:
+		return unmarshalPointers(data, v)
+	case *[]*uint16:
+		return unmarshalPointers(data, v)
+	case *[]*uint32:
+		return unmarshalPointers(data, v)
+	case *[]*uint64:
+		return unmarshalPointers(data, v)
+	case *[]*int8:
+		return unmarshalPointers(data, v)
+	case *[]*int16:
+		return unmarshalPointers(data, v)
+	case *[]*int32:
+		return unmarshalPointers(data, v)
+	case *[]*int64:
+		return unmarshalPointers(data, v)
+	case *[]*float32:
+		return unmarshalPointers(data, v)
+	case *[]*float64:
+		return unmarshalPointers(data, v)
+	case *[]*complex64:
+		return unmarshalPointers(data, v)
+	case *[]*complex128:
+		return unmarshalPointers(data, v)
+	case *[]*Int128:
+		return unmarshalPointers(data, v)
+	case *[]*UInt128:
+		return unmarshalPointers(data, v)
+	case *[]*Float16:
+		return unmarshalPointers(data, v)
 	default:
+		if isStructPointer(v) {
+			return unmarshalStruct(data, reflect.ValueOf(v).Elem())
+		}
+		if isMapPointer(v) {
+			return unmarshalMap(data, reflect.ValueOf(v).Elem())
+		}
 		return fmt.Errorf("invalid type, not supported %T", v)
 	}
 
@@ -329,7 +966,7 @@ func unmarshal(data *jlValue, x any) error {
 // marshalMat is a generic serialization of input matrix to julia value.
 // since type casting to pointer of T is required, it seems it is
 // required to parametrize the pointer of T!
-func marshalMat[T PrimitiveTypes, PtrT *T](v *Mat[T]) (*jlValue, error) {
+func marshalMat[T PrimitiveTypes, PtrT *T](v *Mat[T]) (*Value, error) {
 	n := uint64(len(v.dims))
 	var el T
 
@@ -339,153 +976,536 @@ func marshalMat[T PrimitiveTypes, PtrT *T](v *Mat[T]) (*jlValue, error) {
 		return nil, fmt.Errorf("could not allocate array: %w", err)
 	}
 
-	data := array.data
-	ptr := unsafe.Pointer(data)
+	ptr := C.gojl_array_data((*C.jl_value_t)(unsafe.Pointer(array)))
 
 	for i := range v.elms {
 		p := (PtrT)(unsafe.Pointer(uintptr(ptr) + uintptr(i)*unsafe.Sizeof(el)))
 		*p = v.elms[i]
 	}
 
-	return &jlValue{value: (*(C.jl_value_t))(unsafe.Pointer(array))}, nil
+	return &Value{value: (*(C.jl_value_t))(unsafe.Pointer(array))}, nil
 }
 
 // unmarshalMat is a generic way to unmarshal julia value into matrix type
 // type-parametrized by primitive types. interestingly, we need to
 // type-parametrize this function using both T and its pointer.
-func unmarshalMat[T PrimitiveTypes, PtrT *T](jlValue *jlValue, v *Mat[T]) {
+//
+// dimensions are read from julia array. an empty matrix, i.e. one without
+// dims, is populated with dims and elements of the julia array, whereas
+// a preallocated matrix must match the shape of julia array.
+func unmarshalMat[T PrimitiveTypes, PtrT *T](data *Value, v *Mat[T]) error {
 	var el T
-	value := jlValue.value
+	value := data.value
 
-	// cast value as unsafe pointer first, which makes it
-	// equivalent to void* in C, then cast it to
-	// pointer of jl_array_t
-	array := (*(C.jl_array_t))(unsafe.Pointer(value))
+	dims := make([]int, int(C.gojl_array_rank(value)))
+	for i := range dims {
+		dims[i] = int(C.gojl_array_dim(value, C.int(i)))
+	}
 
-	// access the data field
-	data := array.data
+	if len(v.dims) == 0 {
+		numElements := 1
+		for _, dim := range dims {
+			numElements *= dim
+		}
+		v.dims = dims
+		v.elms = make([]T, numElements)
+	} else if !equalDims(v.dims, dims) {
+		return fmt.Errorf("%w: cannot unmarshal julia array of dims %v into mat of dims %v",
+			ErrShapeMismatch, dims, v.dims)
+	}
 
-	// cast it as unsafe pointer in order to perform
-	// pointer arithmetics
-	ptr := unsafe.Pointer(data)
+	// access the data via accessor rather than the data field of
+	// jl_array_t, whose layout depends on julia version
+	ptr := C.gojl_array_data(value)
 
-	// better be sure that returned data is of that specific length
+	// length of elements is guaranteed to match julia array by now
 	for i := range v.elms {
 		// https://stackoverflow.com/a/49961256
 		p := (PtrT)(unsafe.Pointer(uintptr(ptr) + uintptr(i)*unsafe.Sizeof(el)))
 		(*v).elms[i] = *p
 	}
+
+	return nil
+}
+
+// newBits boxes go value x into julia value of the type corresponding to
+// go type of x. memory layout of both types must match, which is the case
+// for complex numbers stored as real part followed by imaginary part
+func newBits[T any](x T) (*Value, error) {
+	dataType, err := getDataType(x)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Value{value: C.jl_new_bits(dataType, unsafe.Pointer(&x))}, nil
+}
+
+// marshalBigInt parses decimal representation of x as julia BigInt
+func marshalBigInt(x *big.Int) (*Value, error) {
+	bigInt, err := getDataType(x)
+	if err != nil {
+		return nil, err
+	}
+
+	parse, err := getFunction("parse", ModuleBase)
+	if err != nil {
+		return nil, err
+	}
+
+	return call(parse, bigInt, newString(x.String()))
+}
+
+// bigFloatPrec is precision of julia BigFloat created from big.Float of
+// zero precision, which matches precision of float64 values converted to
+// big.Float
+const bigFloatPrec = 53
+
+// marshalBigFloat parses decimal representation of x as julia BigFloat
+// of the same precision. shortest representation that is exact for the
+// precision of x is used, hence no precision is lost
+func marshalBigFloat(x *big.Float) (*Value, error) {
+	f, err := getFunction(jlBigFloat, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	// string is rooted since boxing precision may trigger garbage collection
+	s := &Value{value: newString(x.Text('g', -1))}
+	if err := root(s); err != nil {
+		return nil, err
+	}
+	defer func() { _ = release(s) }()
+
+	// zero value of big.Float has no precision, which julia rejects
+	prec := x.Prec()
+	if prec == 0 {
+		prec = bigFloatPrec
+	}
+
+	return call(f, s.value, C.jl_box_int64(C.long(prec)))
+}
+
+// unmarshalBigInt reads julia BigInt via its decimal representation
+func unmarshalBigInt(data *Value, x *big.Int) error {
+	s, err := evalFunc("string", ModuleBase, data)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := x.SetString(goString(s.value), 10); !ok {
+		return fmt.Errorf("invalid julia BigInt %s", goString(s.value))
+	}
+
+	return nil
+}
+
+// unmarshalBigFloat reads julia BigFloat via its decimal representation,
+// setting precision of x to that of julia value
+func unmarshalBigFloat(data *Value, x *big.Float) error {
+	resp, err := evalFunc("precision", ModuleBase, data)
+	if err != nil {
+		return err
+	}
+
+	// precision is unboxed before calling julia again, which may collect
+	// the box
+	prec := uint(C.jl_unbox_int64(resp.value))
+
+	s, err := evalFunc("string", ModuleBase, data)
+	if err != nil {
+		return err
+	}
+
+	x.SetPrec(prec).SetMode(big.ToNearestEven)
+	if _, _, err := x.Parse(goString(s.value), 10); err != nil {
+		return fmt.Errorf("invalid julia BigFloat %s: %w", goString(s.value), err)
+	}
+
+	return nil
+}
+
+// marshalStrings packs strings into julia Vector{String}. Strings are
+// pushed to the vector as soon as they are created, so that they remain
+// referenced by julia, whereas the vector is rooted until it is filled
+func marshalStrings(v []string) (*Value, error) {
+	arrayType, err := getArrayType(1, "")
+	if err != nil {
+		return nil, err
+	}
+
+	array := C.jl_alloc_array_1d(arrayType, 0)
+	value := (*C.jl_value_t)(unsafe.Pointer(array))
+
+	// rooted via a separate value, since release marks it released
+	tmp := &Value{value: value}
+	if err := root(tmp); err != nil {
+		return nil, err
+	}
+	defer func() { _ = release(tmp) }()
+
+	sizeHint, err := getFunction("sizehint!", ModuleBase)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := call(sizeHint, value, C.jl_box_int64(C.long(len(v)))); err != nil {
+		return nil, err
+	}
+
+	push, err := getFunction("push!", ModuleBase)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range v {
+		if _, err := call(push, value, newString(v[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Value{value: value}, nil
+}
+
+// unmarshalStrings reads julia Vector{String} into string slice
+func unmarshalStrings(data *Value, v *[]string) error {
+	value := data.value
+
+	out := make([]string, int(C.gojl_array_dim(value, 0)))
+	for i := range out {
+		s := C.gojl_array_ptr_ref(value, C.size_t(i))
+		if s == nil {
+			return fmt.Errorf("%w: cannot unmarshal undefined element %d of julia %s",
+				ErrTypeMismatch, i+1, typeOf(data))
+		}
+
+		out[i] = goString(s)
+	}
+
+	*v = out
+	return nil
+}
+
+// newSymbol returns julia symbol of the name
+func newSymbol(name Symbol) *C.jl_value_t {
+	cName := C.CString(string(name))
+	defer C.free(unsafe.Pointer(cName))
+
+	return (*C.jl_value_t)(unsafe.Pointer(C.jl_symbol(cName)))
+}
+
+// getMissing returns julia missing, which is not exported by julia C API
+func getMissing() (*Value, error) {
+	missing, err := getFunction("missing", ModuleBase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Value{value: missing}, nil
+}
+
+// isMissing checks if value is julia missing, which is a singleton
+func isMissing(value *C.jl_value_t) bool {
+	missing, err := getMissing()
+	if err != nil {
+		return false
+	}
+
+	return value == missing.value
+}
+
+// newString creates julia String from go string, which may contain
+// null bytes, hence the length is passed explicitly
+func newString(s string) *C.jl_value_t {
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+
+	return C.jl_pchar_to_string(cs, C.size_t(len(s)))
+}
+
+// goString copies julia String into go string
+func goString(s *C.jl_value_t) string {
+	return C.GoStringN(C.jl_string_ptr(s), C.int(C.gojl_string_len(s)))
+}
+
+// equalDims checks if two dimension slices are the same
+func equalDims(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
 }
 
 // getArrayType takes element el as empty interface type because we can't do
-// type switch on generics!
+// type switch on generics! bool arrays are represented as Int8 arrays
 func getArrayType(n uint64, el any) (*C.jl_value_t, error) {
+	if _, ok := el.(bool); ok {
+		el = int8(0)
+	}
+
+	elType, err := getDataType(el)
+	if err != nil {
+		return nil, err
+	}
+
+	return C.jl_apply_array_type(elType, C.ulong(n)), nil
+}
+
+// getDataType returns julia data type corresponding to go primitive type of el
+func getDataType(el any) (*C.jl_value_t, error) {
+	var dataType *C.jl_datatype_t
 	switch el.(type) {
 	case bool:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_int8_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_bool_type()
 	case uint8:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_uint8_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_uint8_type()
 	case uint16:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_uint16_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_uint16_type()
 	case uint32:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_uint32_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_uint32_type()
 	case uint64:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_uint64_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_uint64_type()
 	case int8:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_int8_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_int8_type()
 	case int16:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_int16_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_int16_type()
 	case int32:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_int32_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_int32_type()
 	case int64:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_int64_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_int64_type()
 	case float32:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_float32_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_float32_type()
 	case float64:
-		return C.jl_apply_array_type(
-			(*(C.jl_value_t))(
-				unsafe.Pointer(
-					C.jl_float64_type,
-				),
-			),
-			C.ulong(n),
-		), nil
+		dataType = C.gojl_float64_type()
+	case complex64:
+		// complex, 128-bit and big number types are not exported by
+		// julia C API, hence are looked up by their names in Base module
+		return getFunction("ComplexF32", ModuleBase)
+	case complex128:
+		return getFunction("ComplexF64", ModuleBase)
+	case Int128:
+		return getFunction("Int128", ModuleBase)
+	case UInt128:
+		return getFunction("UInt128", ModuleBase)
+	case Float16:
+		dataType = C.gojl_float16_type()
+	case *big.Int:
+		return getFunction("BigInt", ModuleBase)
+	case *big.Float:
+		return getFunction("BigFloat", ModuleBase)
+	case string:
+		dataType = C.gojl_string_type()
+	case Symbol:
+		dataType = C.gojl_symbol_type()
+	case Char:
+		dataType = C.gojl_char_type()
 	default:
 		return nil, fmt.Errorf("invalid type, not supported %T", el)
 	}
+
+	return (*(C.jl_value_t))(unsafe.Pointer(dataType)), nil
+}
+
+// isNilPointer checks if x is a typed nil pointer, which is unmarshaled
+// into only from nothing
+func isNilPointer(x any) bool {
+	v := reflect.ValueOf(x)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// checkType verifies that runtime julia type of data matches go type of x,
+// which is a pointer to primitive type or a Mat. Unboxing or reading array
+// data of a mismatched type would otherwise result in a segfault
+func checkType(data *Value, x any) error {
+	if data == nil || data.value == nil {
+		return fmt.Errorf("%w: cannot unmarshal null julia value into %T", ErrTypeMismatch, x)
+	}
+
+	if data.released {
+		return ErrReleased
+	}
+
+	// typed nil pointers cannot be dereferenced to check or populate,
+	// hence they only accept nothing the way nil interface does
+	if isNilPointer(x) {
+		if data.value == C.gojl_nothing() {
+			return nil
+		}
+		return fmt.Errorf("%w: cannot unmarshal julia %s into nil %T", ErrTypeMismatch, typeOf(data), x)
+	}
+
+	value := data.value
+	ok := false
+	switch v := x.(type) {
+	case nil, *NothingType:
+		ok = value == C.gojl_nothing()
+	case *MissingType:
+		ok = isMissing(value)
+	case *Symbol:
+		ok = isType(value, *v)
+	case *Char:
+		ok = isType(value, *v)
+	case *bool:
+		ok = isType(value, *v)
+	case *uint8:
+		ok = isType(value, *v)
+	case *uint16:
+		ok = isType(value, *v)
+	case *uint32:
+		ok = isType(value, *v)
+	case *uint64:
+		ok = isType(value, *v)
+	case *int8:
+		ok = isType(value, *v)
+	case *int16:
+		ok = isType(value, *v)
+	case *int32:
+		ok = isType(value, *v)
+	case *int64:
+		ok = isType(value, *v)
+	case *float32:
+		ok = isType(value, *v)
+	case *float64:
+		ok = isType(value, *v)
+	case *complex64:
+		ok = isType(value, *v)
+	case *complex128:
+		ok = isType(value, *v)
+	case *Int128:
+		ok = isType(value, *v)
+	case *UInt128:
+		ok = isType(value, *v)
+	case *Float16:
+		ok = isType(value, *v)
+	case *big.Int:
+		ok = isType(value, v)
+	case *big.Float:
+		ok = isType(value, v)
+	case *string:
+		ok = isType(value, *v)
+	case *[]string:
+		ok = isArrayOf(value, "", 1)
+	case *Mat[bool]:
+		// bool matrices are marshaled as Int8 arrays, however, julia Bool
+		// arrays share the same memory layout
+		ok = isArrayOf(value, int8(0), len(v.dims)) || isArrayOf(value, true, len(v.dims))
+	case *Mat[uint8]:
+		ok = isArrayType(value, v)
+	case *Mat[uint16]:
+		ok = isArrayType(value, v)
+	case *Mat[uint32]:
+		ok = isArrayType(value, v)
+	case *Mat[uint64]:
+		ok = isArrayType(value, v)
+	case *Mat[int8]:
+		ok = isArrayType(value, v)
+	case *Mat[int16]:
+		ok = isArrayType(value, v)
+	case *Mat[int32]:
+		ok = isArrayType(value, v)
+	case *Mat[int64]:
+		ok = isArrayType(value, v)
+	case *Mat[float32]:
+		ok = isArrayType(value, v)
+	case *Mat[float64]:
+		ok = isArrayType(value, v)
+	case *Mat[complex64]:
+		ok = isArrayType(value, v)
+	case *Mat[complex128]:
+		ok = isArrayType(value, v)
+	case *Mat[Int128]:
+		ok = isArrayType(value, v)
+	case *Mat[UInt128]:
+		ok = isArrayType(value, v)
+	case *Mat[Float16]:
+		ok = isArrayType(value, v)
+	case maskedMat:
+		ok = v.isArrayType(value)
+	case *[]*bool:
+		ok = isPointersType(value, v)
+	case *[]*uint8:
+		ok = isPointersType(value, v)
+	case *[]*uint16:
+		ok = isPointersType(value, v)
+	case *[]*uint32:
+		ok = isPointersType(value, v)
+	case *[]*uint64:
+		ok = isPointersType(value, v)
+	case *[]*int8:
+		ok = isPointersType(value, v)
+	case *[]*int16:
+		ok = isPointersType(value, v)
+	case *[]*int32:
+		ok = isPointersType(value, v)
+	case *[]*int64:
+		ok = isPointersType(value, v)
+	case *[]*float32:
+		ok = isPointersType(value, v)
+	case *[]*float64:
+		ok = isPointersType(value, v)
+	case *[]*complex64:
+		ok = isPointersType(value, v)
+	case *[]*complex128:
+		ok = isPointersType(value, v)
+	case *[]*Int128:
+		ok = isPointersType(value, v)
+	case *[]*UInt128:
+		ok = isPointersType(value, v)
+	case *[]*Float16:
+		ok = isPointersType(value, v)
+	default:
+		switch {
+		case isStructPointer(v):
+			ok = isStruct(value)
+		case isMapPointer(v):
+			ok = isDict(value)
+		default:
+			return fmt.Errorf("invalid type, not supported %T", v)
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, typeOf(data), x)
+	}
+
+	return nil
+}
+
+// isType checks if julia value is of the type corresponding to go primitive el
+func isType(value *C.jl_value_t, el any) bool {
+	return isSameType(C.gojl_typeof(value), el)
+}
+
+// isArrayType checks if julia value is an array matching element type
+// and rank of the matrix
+func isArrayType[T PrimitiveTypes](value *C.jl_value_t, v *Mat[T]) bool {
+	var el T
+	return isArrayOf(value, any(el), len(v.dims))
+}
+
+// isArrayOf checks if julia value is an array of rank n with element type
+// equivalent to go primitive type of el. rank is not checked when n is 0
+func isArrayOf(value *C.jl_value_t, el any, n int) bool {
+	if C.gojl_is_array(value) == 0 {
+		return false
+	}
+
+	// an empty matrix accepts an array of any rank
+	if n > 0 && int(C.gojl_array_rank(value)) != n {
+		return false
+	}
+
+	return isSameType(C.gojl_array_eltype(value), el)
 }
 
-// dim2NumElms returns total number of elements inferred by dimension sizes
+// dim2NumElms returns total number of elements inferred by dimension sizes.
+// Dimensions of zero size are valid, such as for empty vectors
 func dim2NumElms(dims []int) (int, error) {
 	var numElements int
 	if len(dims) == 0 {
@@ -493,8 +1513,8 @@ func dim2NumElms(dims []int) (int, error) {
 	}
 
 	for i, dim := range dims {
-		if dim <= 0 {
-			return 0, fmt.Errorf("invalid dims, needs to be greater than 0")
+		if dim < 0 {
+			return 0, fmt.Errorf("invalid dims, needs to be non-negative")
 		}
 		if i == 0 {
 			numElements = dim
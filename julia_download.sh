@@ -1,19 +1,23 @@
 #!/usr/bin/env bash
+# JULIA_VERSION selects julia release to install, 1.7 or newer is supported
+JULIA_VERSION=${JULIA_VERSION:-1.10.5}
+JULIA_MINOR=${JULIA_VERSION%.*}
 ARCH=$(uname -m)
 
 if [[ "${ARCH}" == "aarch64" ]]; then
-	wget -q https://julialang-s3.julialang.org/bin/linux/aarch64/1.7/julia-1.7.2-linux-aarch64.tar.gz
-	tar -zxf julia-1.7.2-linux-aarch64.tar.gz
-	rm -rf julia-1.7.2-linux-aarch64.tar.gz
+	PLATFORM=aarch64
+	TARBALL=julia-${JULIA_VERSION}-linux-aarch64.tar.gz
 elif [[ "${ARCH}" == "x86_64" ]]; then
-	wget -q https://julialang-s3.julialang.org/bin/linux/x64/1.7/julia-1.7.2-linux-x86_64.tar.gz
-	tar -zxf julia-1.7.2-linux-x86_64.tar.gz
-	rm -rf julia-1.7.2-linux-x86_64.tar.gz
+	PLATFORM=x64
+	TARBALL=julia-${JULIA_VERSION}-linux-x86_64.tar.gz
 else
 	echo "unsupported platform ${ARCH}"
 	exit 1
 fi
 
-mv julia-1.7.2 /usr/local/julia
-ln -s /usr/local/julia/bin/julia /usr/local/bin
+wget -q https://julialang-s3.julialang.org/bin/linux/${PLATFORM}/${JULIA_MINOR}/${TARBALL}
+tar -zxf ${TARBALL}
+rm -rf ${TARBALL}
 
+mv julia-${JULIA_VERSION} /usr/local/julia
+ln -s /usr/local/julia/bin/julia /usr/local/bin
@@ -1,10 +1,32 @@
 package julia
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"os"
+	"strings"
 	"testing"
 )
 
+// TestMain initializes julia runtime once for all tests since julia
+// cannot be initialized again in the same process once finalized
+func TestMain(m *testing.M) {
+	if err := Initialize(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	if err := Finalize(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	os.Exit(code)
+}
+
 func TestNewMatInstantiation(t *testing.T) {
 	if _, err := NewMat([]byte{1, 2, 3, 4}, 4); err != nil {
 		t.Fatal(err)
@@ -21,12 +43,17 @@ func TestNewMatInstantiation(t *testing.T) {
 	if _, err := NewMat([]byte{1, 2, 3, 4}, 2, 3); err == nil {
 		t.Fatal("should have failed for dim 2x3")
 	}
+
+	if _, err := NewMat([]byte{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewMat([]byte{}, -1); err == nil {
+		t.Fatal("should have failed for dim -1")
+	}
 }
 
 func TestMarshalPrimitiveTypes(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	if _, err := Marshal(true); err != nil {
 		t.Fatal(err)
 	}
@@ -66,8 +93,6 @@ func TestMarshalPrimitiveTypes(t *testing.T) {
 }
 
 func TestMarshalSlices(t *testing.T) {
-	Initialize()
-	defer Finalize()
 	if _, err := Marshal([]bool{true, true, false, true}); err != nil {
 		t.Fatal(err)
 	}
@@ -111,12 +136,18 @@ func TestMarshalSlices(t *testing.T) {
 	if _, err := Marshal([]float64{1, 2, 3, 4}); err != nil {
 		t.Fatal(err)
 	}
+
+	empty, err := Marshal([]float64{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := empty.Type(); typeName != "Vector{Float64}" {
+		t.Fatal("expected Vector{Float64}, got", typeName)
+	}
 }
 
 func TestMarshalMultiDimensional(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	if mat, err := NewMat([]bool{true, true, false, true}, 2, 2); err != nil {
 		t.Fatal(err)
 	} else {
@@ -207,9 +238,6 @@ func TestMarshalMultiDimensional(t *testing.T) {
 }
 
 func TestEvalFuncPrintlnBase(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	mat, err := NewMat([]float64{1, 2, 3, 4}, 2, 2)
 	if err != nil {
 		t.Fatal(err)
@@ -224,12 +252,17 @@ func TestEvalFuncPrintlnBase(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	if !data.IsNothing() {
+		t.Fatal("expected println to return nothing, got", data.Type())
+	}
+
+	if err := Unmarshal(data, &Nothing); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestEvalFuncRandn(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	mat, err := NewMat([]int8{2, 2})
 	if err != nil {
 		t.Fatal(err)
@@ -247,9 +280,6 @@ func TestEvalFuncRandn(t *testing.T) {
 }
 
 func TestEval(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	if _, err := Eval("f(x::Vector{Int64}) = println(randn(x...))"); err != nil {
 		t.Fatal(err)
 	}
@@ -270,9 +300,6 @@ func TestEval(t *testing.T) {
 }
 
 func TestUnmarshalOutputOfRandn2x3(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	// testing randn(2,3)
 	n, m := 2, 3
 
@@ -304,9 +331,6 @@ func TestUnmarshalOutputOfRandn2x3(t *testing.T) {
 }
 
 func TestTypeofSlice(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	{
 		arg, err := Marshal([]bool{true, true, false, true})
 		if err != nil {
@@ -452,9 +476,6 @@ func TestTypeofSlice(t *testing.T) {
 }
 
 func TestTypeofMatrix(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	{
 		x, err := NewMat([]bool{true, true, false, true}, 2, 2)
 		if err != nil {
@@ -655,9 +676,6 @@ func TestTypeofMatrix(t *testing.T) {
 }
 
 func TestTypeofTensor(t *testing.T) {
-	Initialize()
-	defer Finalize()
-
 	{
 		x, err := NewMat(make([]bool, 2*3*4), 2, 3, 4)
 		if err != nil {
@@ -712,3 +730,602 @@ func TestTypeofTensor(t *testing.T) {
 		}
 	}
 }
+
+func TestMarshalHigherRankTensor(t *testing.T) {
+	// batch, channel, height and width
+	dims := []int{2, 3, 4, 5}
+	elms := make([]float32, 2*3*4*5)
+	for i := range elms {
+		elms[i] = float32(i)
+	}
+
+	x, err := NewMat(elms, dims...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if argType := arg.Type(); argType != "Array{Float32, 4}" {
+		t.Fatal("expected Array{Float32, 4}, got", argType)
+	}
+
+	// julia arrays are column major, i.e. x[2, 1, 1, 1] is the second element
+	idx := make([]*Value, 0, len(dims)+1)
+	idx = append(idx, arg)
+	for _, i := range []int64{2, 1, 1, 1} {
+		v, err := Marshal(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idx = append(idx, v)
+	}
+
+	resp, err := EvalFunc("getindex", ModuleBase, idx...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var el float32
+	if err := Unmarshal(resp, &el); err != nil {
+		t.Fatal(err)
+	}
+
+	if el != 1 {
+		t.Fatal("expected x[2, 1, 1, 1] to be 1, got", el)
+	}
+
+	y := new(Mat[float32])
+	if err := Unmarshal(arg, y); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalDims(y.GetDims(), dims) {
+		t.Fatal("expected dims", dims, "got", y.GetDims())
+	}
+
+	for i := range elms {
+		if y.GetElms()[i] != elms[i] {
+			t.Fatal("expected elements to round trip, got", y.GetElms())
+		}
+	}
+
+	resp, err = Eval("ones(Int16, 1, 2, 3, 4, 5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z := new(Mat[int16])
+	if err := Unmarshal(resp, z); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalDims(z.GetDims(), []int{1, 2, 3, 4, 5}) || z.GetElms()[len(z.GetElms())-1] != 1 {
+		t.Fatal("expected 5 dimensional array of ones, got", z.GetDims(), z.GetElms())
+	}
+}
+
+func TestUnmarshalTypeMismatch(t *testing.T) {
+	arg, err := Marshal(float64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int64
+	if err := Unmarshal(arg, &n); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch unmarshaling Float64 into int64, got", err)
+	}
+
+	x, err := NewMat([]float32{1, 2, 3, 4}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err = Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y, err := NewMat(make([]float64, 4), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(arg, y); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch unmarshaling Matrix{Float32} into Mat[float64], got", err)
+	}
+
+	z, err := NewMat(make([]float32, 4), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(arg, z); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch unmarshaling Matrix{Float32} into 1-D Mat, got", err)
+	}
+
+	if err := Unmarshal(arg, x); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnmarshalNilPointer(t *testing.T) {
+	arg, err := Marshal(float64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(arg, (*float64)(nil)); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch unmarshaling into nil *float64, got", err)
+	}
+
+	if err := Unmarshal(arg, (*Mat[float64])(nil)); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch unmarshaling into nil *Mat[float64], got", err)
+	}
+
+	arg, err = Marshal(Nothing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(arg, (*float64)(nil)); err != nil {
+		t.Fatal("expected nothing to unmarshal into nil *float64, got", err)
+	}
+
+	if err := Unmarshal(arg, (*Mat[float64])(nil)); err != nil {
+		t.Fatal("expected nothing to unmarshal into nil *Mat[float64], got", err)
+	}
+}
+
+func TestUnmarshalIntoEmptyMat(t *testing.T) {
+	resp, err := Eval("reshape(collect(1.0:24.0), 2, 3, 4)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mat := new(Mat[float64])
+	if err := Unmarshal(resp, mat); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalDims(mat.GetDims(), []int{2, 3, 4}) {
+		t.Fatal("expected dims [2 3 4], got", mat.GetDims())
+	}
+
+	for i, elm := range mat.GetElms() {
+		if elm != float64(i+1) {
+			t.Fatal("expected elements in column major order, got", mat.GetElms())
+		}
+	}
+}
+
+func TestUnmarshalShapeMismatch(t *testing.T) {
+	resp, err := Eval("randn(2, 3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mat, err := NewMat(make([]float64, 6), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(resp, mat); !errors.Is(err, ErrShapeMismatch) {
+		t.Fatal("expected ErrShapeMismatch, got", err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	v, err := Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(v, "1.") {
+		t.Fatal("expected julia 1.x version, got", v)
+	}
+}
+
+func TestMarshalString(t *testing.T) {
+	// strings may contain null bytes and multibyte characters
+	input := "abcd\x00αβγ"
+
+	arg, err := Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "String" {
+		t.Fatal("expected String, got", typeName)
+	}
+
+	resp, err := EvalFunc("reverse", ModuleBase, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output string
+	if err := Unmarshal(resp, &output); err != nil {
+		t.Fatal(err)
+	}
+
+	if output != "γβα\x00dcba" {
+		t.Fatal("did not receive expected value", output)
+	}
+
+	var n int64
+	if err := Unmarshal(resp, &n); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestMarshalStringSlice(t *testing.T) {
+	arg, err := Marshal([]string{"abcd", "", "12345678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Vector{String}" {
+		t.Fatal("expected Vector{String}, got", typeName)
+	}
+
+	if _, err := Eval(`upper(x) = uppercase.(x)`); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := EvalFunc("upper", ModuleMain, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var list []string
+	if err := Unmarshal(resp, &list); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 3 || list[0] != "ABCD" || list[1] != "" || list[2] != "12345678" {
+		t.Fatal("did not receive expected values", list)
+	}
+
+	resp, err = Eval("Vector{String}(undef, 2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(resp, &list); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch for undefined elements, got", err)
+	}
+}
+
+func TestMarshalComplex(t *testing.T) {
+	arg, err := Marshal(complex64(complex(3, 4)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "ComplexF32" {
+		t.Fatal("expected ComplexF32, got", typeName)
+	}
+
+	resp, err := EvalFunc("conj", ModuleBase, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var z complex64
+	if err := Unmarshal(resp, &z); err != nil {
+		t.Fatal(err)
+	}
+
+	if z != complex(3, -4) {
+		t.Fatal("expected 3-4im, got", z)
+	}
+
+	var w complex128
+	if err := Unmarshal(resp, &w); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestUnmarshalComplexEigenvalues(t *testing.T) {
+	// rotation matrix has eigenvalues ±im
+	mat, err := NewMat([]float64{0, 1, -1, 0}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := Marshal(mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Eval("using LinearAlgebra"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := EvalFunc("eigvals", ModuleMain, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := resp.Type(); typeName != "Vector{ComplexF64}" {
+		t.Fatal("expected Vector{ComplexF64}, got", typeName)
+	}
+
+	eig := new(Mat[complex128])
+	if err := Unmarshal(resp, eig); err != nil {
+		t.Fatal(err)
+	}
+
+	if elms := eig.GetElms(); len(elms) != 2 || elms[0] != complex(0, -1) || elms[1] != complex(0, 1) {
+		t.Fatal("did not receive expected values", elms)
+	}
+
+	// complex matrices round trip with the same memory layout
+	spectrum, err := NewMat([]complex128{1 + 2i, 3 - 4i, -5i, 6}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err = Marshal(spectrum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Matrix{ComplexF64}" {
+		t.Fatal("expected Matrix{ComplexF64}, got", typeName)
+	}
+
+	out := new(Mat[complex128])
+	if err := Unmarshal(arg, out); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range spectrum.GetElms() {
+		if spectrum.GetElms()[i] != out.GetElms()[i] {
+			t.Fatal("expected", spectrum.GetElms(), "got", out.GetElms())
+		}
+	}
+}
+
+func TestMarshalInt128(t *testing.T) {
+	resp, err := Eval("typemax(Int128)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var x Int128
+	if err := Unmarshal(resp, &x); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.String() != "170141183460469231731687303715884105727" {
+		t.Fatal("expected typemax(Int128), got", x)
+	}
+
+	arg, err := Marshal([]UInt128{{Lo: 1}, {Hi: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Vector{UInt128}" {
+		t.Fatal("expected Vector{UInt128}, got", typeName)
+	}
+
+	resp, err = EvalFunc("sum", ModuleBase, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var y UInt128
+	if err := Unmarshal(resp, &y); err != nil {
+		t.Fatal(err)
+	}
+
+	if y.Lo != 1 || y.Hi != 1 {
+		t.Fatal("expected 2^64 + 1, got", y)
+	}
+}
+
+func TestUnmarshalFloat16(t *testing.T) {
+	resp, err := Eval("Float16[0.5 1.0; -2.0 65504.0]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mat := new(Mat[Float16])
+	if err := Unmarshal(resp, mat); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []float32{0.5, -2, 1, 65504}
+	for i, h := range mat.GetElms() {
+		if h.Float32() != expected[i] {
+			t.Fatal("expected", expected, "got", mat.GetElms())
+		}
+	}
+
+	// Float16 arrays are not mistaken for UInt16 arrays of the same layout
+	if err := Unmarshal(resp, new(Mat[uint16])); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestMarshalBigNumbers(t *testing.T) {
+	n, err := Marshal(big.NewInt(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := n.Type(); typeName != "BigInt" {
+		t.Fatal("expected BigInt, got", typeName)
+	}
+
+	resp, err := EvalFunc("factorial", ModuleBase, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := new(big.Int)
+	if err := Unmarshal(resp, x); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.String() != "265252859812191058636308480000000" {
+		t.Fatal("expected 30!, got", x)
+	}
+
+	f, _, err := big.ParseFloat("2", 10, 256, big.ToNearestEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = EvalFunc("sqrt", ModuleBase, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y := new(big.Float)
+	if err := Unmarshal(resp, y); err != nil {
+		t.Fatal(err)
+	}
+
+	if y.Prec() != 256 {
+		t.Fatal("expected precision 256, got", y.Prec())
+	}
+
+	expected := new(big.Float).SetPrec(256).Sqrt(f)
+	if y.Cmp(expected) != 0 {
+		t.Fatal("expected", expected.Text('g', -1), "got", y.Text('g', -1))
+	}
+
+	if err := Unmarshal(resp, new(big.Int)); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestMarshalBigFloatZeroValue(t *testing.T) {
+	v, err := Marshal(new(big.Float))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := v.Type(); typeName != "BigFloat" {
+		t.Fatal("expected BigFloat, got", typeName)
+	}
+
+	x := big.NewFloat(1)
+	if err := Unmarshal(v, x); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Sign() != 0 {
+		t.Fatal("expected zero, got", x.Text('g', -1))
+	}
+
+	if x.Prec() != bigFloatPrec {
+		t.Fatalf("expected precision %d, got %d", bigFloatPrec, x.Prec())
+	}
+}
+
+func TestMarshalSymbolAndChar(t *testing.T) {
+	sym, err := Marshal(Symbol("U"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !sym.IsSymbol() || sym.String() != ":U" {
+		t.Fatal("expected :U, got", sym)
+	}
+
+	resp, err := EvalFunc("string", ModuleBase, sym)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s string
+	if err := Unmarshal(resp, &s); err != nil || s != "U" {
+		t.Fatal("expected U, got", s, err)
+	}
+
+	resp, err = Eval(":L")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var name Symbol
+	if err := Unmarshal(resp, &name); err != nil || name != "L" {
+		t.Fatal("expected L, got", name, err)
+	}
+
+	c, err := Marshal(Char('α'))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsChar() {
+		t.Fatal("expected Char, got", c.Type())
+	}
+
+	resp, err = EvalFunc("uppercase", ModuleBase, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var upper Char
+	if err := Unmarshal(resp, &upper); err != nil || upper != 'Α' {
+		t.Fatal("expected Α, got", upper, err)
+	}
+
+	// Char is not mistaken for Int32 of the same size
+	var n int32
+	if err := Unmarshal(resp, &n); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestMarshalNothingAndMissing(t *testing.T) {
+	x, err := Marshal(Missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.IsMissing() || x.IsNothing() {
+		t.Fatal("expected missing, got", x)
+	}
+
+	resp, err := EvalFunc("ismissing", ModuleBase, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ok bool
+	if err := Unmarshal(resp, &ok); err != nil || !ok {
+		t.Fatal("expected ismissing to be true", err)
+	}
+
+	y, err := current().Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !y.IsNothing() {
+		t.Fatal("expected nothing, got", y)
+	}
+
+	if err := current().Unmarshal(x, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	if err := Unmarshal(x, &Missing); err != nil {
+		t.Fatal(err)
+	}
+}
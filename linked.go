@@ -0,0 +1,20 @@
+//go:build !julia_dlopen
+
+package julia
+
+/*
+// Start with the basic example from https://docs.julialang.org/en/v1/manual/embedding/
+//
+// Obviously the paths below may need to be modified to match your julia install location and version number.
+//
+// Build with julia_dlopen tag to load libjulia at runtime instead, see dlopen.go
+//
+#cgo CFLAGS: -DJULIA_INIT_DIR="/usr/local/julia/lib" -I/usr/local/julia/include/julia
+#cgo LDFLAGS: -L/usr/local/julia/lib/julia  -L/usr/local/julia/lib -Wl,-rpath,/usr/local/julia/lib -ljulia
+*/
+import "C"
+
+// loadLibrary is a no-op since libjulia is linked into the binary
+func loadLibrary(opts *Options) error {
+	return nil
+}
@@ -0,0 +1,226 @@
+package julia
+
+/*
+#include "jlapi.h"
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+)
+
+// mapOf returns map x holds, i.e. for x of a map type or a non-nil
+// pointer to it
+func mapOf(x any) (reflect.Value, bool) {
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	return v, v.Kind() == reflect.Map
+}
+
+// isMapPointer checks if x is a non-nil pointer to a map, which can be
+// unmarshaled into
+func isMapPointer(x any) bool {
+	v := reflect.ValueOf(x)
+	return v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Map
+}
+
+// isDict checks if julia value is an AbstractDict
+func isDict(value *C.jl_value_t) bool {
+	dictType, err := getFunction("AbstractDict", ModuleBase)
+	if err != nil {
+		return false
+	}
+
+	return C.jl_isa(value, dictType) == 1
+}
+
+// elementOf returns map key or value to marshal the way fieldOf does for
+// struct fields. Map elements are not addressable, hence they are copied
+func elementOf(v reflect.Value) any {
+	if v.Kind() == reflect.Struct {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+
+	return fieldOf(v)
+}
+
+// marshalMap creates julia Dict of entries of map v. Key and value types
+// of the dict are those go key and value types are marshaled to. Types
+// that do not determine julia type by themselves, such as matrices of
+// unknown rank, structs or interfaces, are joined from types of marshaled
+// elements instead
+func marshalMap(v reflect.Value) (*Value, error) {
+	keys := make([]*C.jl_value_t, 0, v.Len())
+	values := make([]*C.jl_value_t, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := marshal(elementOf(iter.Key()))
+		if err != nil {
+			return nil, fmt.Errorf("map key %v: %w", iter.Key(), err)
+		}
+
+		// entries are not referenced by julia until they are added to the
+		// dict, hence they are rooted meanwhile
+		tmpKey := &Value{value: key.value}
+		if err := root(tmpKey); err != nil {
+			return nil, err
+		}
+		defer func() { _ = release(tmpKey) }()
+
+		value, err := marshal(elementOf(iter.Value()))
+		if err != nil {
+			return nil, fmt.Errorf("map value of key %v: %w", iter.Key(), err)
+		}
+
+		tmpValue := &Value{value: value.value}
+		if err := root(tmpValue); err != nil {
+			return nil, err
+		}
+		defer func() { _ = release(tmpValue) }()
+
+		keys = append(keys, key.value)
+		values = append(values, value.value)
+	}
+
+	keyType, err := elementType(v.Type().Key(), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	valueType, err := elementType(v.Type().Elem(), values)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := getFunction(jlDict, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	dict, err := call(f, keyType, valueType, C.jl_box_int64(C.long(len(keys))))
+	if err != nil {
+		return nil, err
+	}
+
+	// rooted via a separate value, since release marks it released
+	tmp := &Value{value: dict.value}
+	if err := root(tmp); err != nil {
+		return nil, err
+	}
+	defer func() { _ = release(tmp) }()
+
+	setIndex, err := getFunction("setindex!", ModuleBase)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		if _, err := call(setIndex, dict.value, values[i], keys[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return dict, nil
+}
+
+// elementType returns julia type go type t is marshaled to. Slices are
+// marshaled to vectors of their element type, whereas scalars have the
+// type of their marshaled zero value. julia types of elms are joined for
+// other types, and Any is returned if there are no elements
+func elementType(t reflect.Type, elms []*C.jl_value_t) (*C.jl_value_t, error) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Interface {
+			return getArrayType(1, reflect.Zero(t.Elem()).Interface())
+		}
+	case reflect.Struct:
+		if !isScalarStruct(reflect.Zero(t)) {
+			break
+		}
+		fallthrough
+	default:
+		zero, err := marshal(reflect.Zero(t).Interface())
+		if err != nil {
+			return nil, err
+		}
+		return C.gojl_typeof(zero.value), nil
+	}
+
+	if len(elms) == 0 {
+		return getFunction("Any", ModuleBase)
+	}
+
+	// julia types are unique, hence distinct types are joined only once
+	seen := make(map[*C.jl_value_t]bool)
+	var types []*C.jl_value_t
+	for i := range elms {
+		if elType := C.gojl_typeof(elms[i]); !seen[elType] {
+			seen[elType] = true
+			types = append(types, elType)
+		}
+	}
+
+	f, err := getFunction("typejoin", ModuleBase)
+	if err != nil {
+		return nil, err
+	}
+
+	joined, err := call(f, types...)
+	if err != nil {
+		return nil, err
+	}
+
+	return joined.value, nil
+}
+
+// unmarshalMap adds pairs of julia dict to map v, which is allocated if
+// it is nil. Keys and values are unmarshaled the way struct fields are
+func unmarshalMap(data *Value, v reflect.Value) error {
+	f, err := getFunction(jlPairs, ModuleMain)
+	if err != nil {
+		return err
+	}
+
+	pairs, err := call(f, data.value)
+	if err != nil {
+		return err
+	}
+
+	// keys and values are collected into vectors of boxed elements, which
+	// are referenced only by pairs while being unmarshaled
+	if err := root(pairs); err != nil {
+		return err
+	}
+	defer func() { _ = release(pairs) }()
+
+	keys := C.jl_get_nth_field(pairs.value, 0)
+	values := C.jl_get_nth_field(pairs.value, 1)
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	for i := 0; i < int(C.gojl_array_dim(keys, 0)); i++ {
+		key := reflect.New(v.Type().Key()).Elem()
+		elm := &Value{value: C.gojl_array_ptr_ref(keys, C.size_t(i)), rt: data.rt}
+		if err := unmarshalField(elm, key); err != nil {
+			return fmt.Errorf("dict key %d: %w", i+1, err)
+		}
+
+		value := reflect.New(v.Type().Elem()).Elem()
+		elm = &Value{value: C.gojl_array_ptr_ref(values, C.size_t(i)), rt: data.rt}
+		if err := unmarshalField(elm, value); err != nil {
+			return fmt.Errorf("dict value of key %v: %w", key, err)
+		}
+
+		v.SetMapIndex(key, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package julia
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMarshalMap(t *testing.T) {
+	arg, err := MarshalMap(map[string]float64{"a": 1, "b": 2.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Dict{String, Float64}" {
+		t.Fatal("expected Dict{String, Float64}, got", typeName)
+	}
+
+	resp, err := EvalFunc("getindex", ModuleBase, arg, mustMarshal(t, "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out float64
+	if err := Unmarshal(resp, &out); err != nil || out != 2.5 {
+		t.Fatal("expected 2.5, got", out, err)
+	}
+
+	// types are inferred from go even for empty maps
+	arg, err = MarshalMap(map[Symbol][]int64{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Dict{Symbol, Vector{Int64}}" {
+		t.Fatal("expected Dict{Symbol, Vector{Int64}}, got", typeName)
+	}
+
+	// slice values, including empty ones, are marshaled to vectors
+	arg, err = MarshalMap(map[string][]float64{"a": {1, 2}, "b": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Dict{String, Vector{Float64}}" {
+		t.Fatal("expected Dict{String, Vector{Float64}}, got", typeName)
+	}
+
+	resp, err = EvalFunc("getindex", ModuleBase, arg, mustMarshal(t, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vec Mat[float64]
+	if err := Unmarshal(resp, &vec); err != nil || !equalDims(vec.GetDims(), []int{2}) || vec.GetElms()[1] != 2 {
+		t.Fatal("expected [1.0, 2.0], got", vec.GetElms(), err)
+	}
+
+	mat, err := NewMat([]float64{1, 2, 3, 4}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err = MarshalMap(map[int64]*Mat[float64]{1: mat})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Dict{Int64, Matrix{Float64}}" {
+		t.Fatal("expected Dict{Int64, Matrix{Float64}}, got", typeName)
+	}
+
+	if _, err := MarshalMap(map[int]float64{1: 1}); err == nil {
+		t.Fatal("should have failed for keys of type int")
+	}
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	resp, err := Eval(`Dict("a" => [1.0, 2.0], "b" => [3.0])`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]Mat[float64]
+	if err := UnmarshalMap(resp, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatal("expected 2 entries, got", out)
+	}
+
+	a, b := out["a"], out["b"]
+	if !equalDims(a.GetDims(), []int{2}) || a.GetElms()[1] != 2 || !equalDims(b.GetDims(), []int{1}) {
+		t.Fatal("did not receive expected values", out)
+	}
+
+	// nothing is unmarshaled as nil pointer
+	resp, err = Eval(`Dict(:x => 1.5, :y => nothing)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[Symbol]*float64{"z": nil}
+	if err := UnmarshalMap(resp, &values); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(values) != 3 || *values["x"] != 1.5 || values["y"] != nil {
+		t.Fatal("did not receive expected values", values)
+	}
+
+	var wrong map[string]float64
+	if err := UnmarshalMap(resp, &wrong); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	resp, err = Eval(`[1.0, 2.0]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UnmarshalMap(resp, &wrong); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func mustMarshal(t *testing.T, s string) *Value {
+	t.Helper()
+
+	value, err := Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
@@ -0,0 +1,195 @@
+package julia
+
+/*
+#include "jlapi.h"
+*/
+import "C"
+import "fmt"
+
+// MaskedMat represents a matrix with missing values, which corresponds to
+// julia Array{Union{Missing,T},N}. Elements are accompanied by a validity
+// mask, i.e. an element is missing if it is not valid, in which case its
+// value is ignored. An empty MaskedMat can be used to unmarshal julia
+// arrays of any shape, including arrays without missing values
+type MaskedMat[T PrimitiveTypes] struct {
+	elms  []T
+	valid []bool
+	dims  []int
+}
+
+// NewMaskedMat creates a new instance of masked matrix and validates if
+// length of elements and validity mask is satisfied by the dimensions
+func NewMaskedMat[T PrimitiveTypes](values []T, valid []bool, dims ...int) (*MaskedMat[T], error) {
+	if len(values) != len(valid) {
+		return nil, fmt.Errorf("len elms and len valid mismatch")
+	}
+
+	m, err := NewMat(values, dims...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MaskedMat[T]{
+		elms:  m.elms,
+		valid: valid,
+		dims:  m.dims,
+	}, nil
+}
+
+func (g *MaskedMat[T]) GetElms() []T {
+	return g.elms
+}
+
+func (g *MaskedMat[T]) GetValid() []bool {
+	return g.valid
+}
+
+func (g *MaskedMat[T]) GetDims() []int {
+	return g.dims
+}
+
+// IsMissing checks if element at linear index i is missing
+func (g *MaskedMat[T]) IsMissing(i int) bool {
+	return !g.valid[i]
+}
+
+// maskedMat is implemented by masked matrices of all element types so
+// that they can be handled without type switch on each of them
+type maskedMat interface {
+	marshal() (*Value, error)
+	unmarshal(data *Value) error
+	isArrayType(value *C.jl_value_t) bool
+}
+
+// marshal packs elements and validity mask as julia arrays, which are
+// combined into an array of missing values on julia side, since memory
+// layout of such arrays depends on julia version
+func (g *MaskedMat[T]) marshal() (*Value, error) {
+	elms, err := marshal(&Mat[T]{elms: g.elms, dims: g.dims})
+	if err != nil {
+		return nil, err
+	}
+
+	// elements are rooted while allocating validity mask
+	if err := root(elms); err != nil {
+		return nil, err
+	}
+	defer func() { _ = release(elms) }()
+
+	valid, err := marshal(&Mat[bool]{elms: g.valid, dims: g.dims})
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := getFunction(jlMasked, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	return call(f, elms.value, valid.value)
+}
+
+// unmarshal reads elements and validity mask of julia array of missing
+// values. missing elements are read as zero values. As with Mat, an empty
+// matrix is populated, whereas a preallocated one must match the shape
+func (g *MaskedMat[T]) unmarshal(data *Value) error {
+	m := &Mat[T]{elms: g.elms, dims: g.dims}
+
+	f, err := getFunction(jlCoalesce, ModuleMain)
+	if err != nil {
+		return err
+	}
+
+	elms, err := call(f, data.value)
+	if err != nil {
+		return err
+	}
+
+	// elements are copied before calling julia again
+	if err := unmarshalMat[T, *T](elms, m); err != nil {
+		return err
+	}
+
+	if f, err = getFunction(jlValid, ModuleMain); err != nil {
+		return err
+	}
+
+	valid, err := call(f, data.value)
+	if err != nil {
+		return err
+	}
+
+	mask := &Mat[bool]{elms: g.valid, dims: g.dims}
+	if err := unmarshalMat[bool, *bool](valid, mask); err != nil {
+		return err
+	}
+
+	g.elms, g.valid, g.dims = m.elms, mask.elms, m.dims
+	return nil
+}
+
+// isArrayType checks if julia value is an array matching element type and
+// rank of the matrix, with or without missing values
+func (g *MaskedMat[T]) isArrayType(value *C.jl_value_t) bool {
+	var el T
+	if C.gojl_is_array(value) == 0 {
+		return false
+	}
+
+	if n := len(g.dims); n > 0 && int(C.gojl_array_rank(value)) != n {
+		return false
+	}
+
+	f, err := getFunction("nonmissingtype", ModuleBase)
+	if err != nil {
+		return false
+	}
+
+	elType, err := call(f, C.gojl_array_eltype(value))
+	if err != nil {
+		return false
+	}
+
+	// bool matrices are marshaled as Int8 arrays as Mat[bool]
+	if _, ok := any(el).(bool); ok && isSameType(elType.value, int8(0)) {
+		return true
+	}
+
+	return isSameType(elType.value, el)
+}
+
+// unmarshalPointers reads julia vector with missing values into a slice
+// of pointers, which are nil for missing elements
+func unmarshalPointers[T PrimitiveTypes](data *Value, v *[]*T) error {
+	m := new(MaskedMat[T])
+	if err := m.unmarshal(data); err != nil {
+		return err
+	}
+
+	out := make([]*T, len(m.elms))
+	for i := range out {
+		if m.valid[i] {
+			out[i] = &m.elms[i]
+		}
+	}
+
+	*v = out
+	return nil
+}
+
+// isPointersType checks if julia value is a vector, with or without
+// missing values, that can be read into slice of pointers
+func isPointersType[T PrimitiveTypes](value *C.jl_value_t, _ *[]*T) bool {
+	// dims of a vector, whose length is not checked
+	return (&MaskedMat[T]{dims: []int{0}}).isArrayType(value)
+}
+
+// isSameType checks if julia type t corresponds to go primitive type of el
+func isSameType(t *C.jl_value_t, el any) bool {
+	dataType, err := getDataType(el)
+	if err != nil {
+		return false
+	}
+
+	return C.jl_types_equal(t, dataType) == 1
+}
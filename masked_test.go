@@ -0,0 +1,105 @@
+package julia
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewMaskedMatInstantiation(t *testing.T) {
+	if _, err := NewMaskedMat([]float64{1, 2, 3, 4}, []bool{true, false, true, true}, 2, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewMaskedMat([]float64{1, 2, 3, 4}, []bool{true, false}, 2, 2); err == nil {
+		t.Fatal("should have failed for validity mask of length 2")
+	}
+
+	if _, err := NewMaskedMat([]float64{1, 2, 3, 4}, []bool{true, false, true, true}, 2, 3); err == nil {
+		t.Fatal("should have failed for dim 2x3")
+	}
+}
+
+func TestMarshalMaskedMat(t *testing.T) {
+	mat, err := NewMaskedMat([]float64{1, 0, 3, 4}, []bool{true, false, true, true}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := Marshal(mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Matrix{Union{Missing, Float64}}" {
+		t.Fatal("expected Matrix{Union{Missing, Float64}}, got", typeName)
+	}
+
+	if _, err := Eval(`double(x) = 2 .* x`); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := EvalFunc("double", ModuleMain, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(MaskedMat[float64])
+	if err := Unmarshal(resp, out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalDims(out.GetDims(), []int{2, 2}) {
+		t.Fatal("expected dims [2 2], got", out.GetDims())
+	}
+
+	expected := []float64{2, 0, 6, 8}
+	for i := range expected {
+		if out.IsMissing(i) != mat.IsMissing(i) || out.GetElms()[i] != expected[i] {
+			t.Fatal("expected", expected, mat.GetValid(), "got", out.GetElms(), out.GetValid())
+		}
+	}
+
+	// arrays with missing values cannot be read into plain matrices
+	if err := Unmarshal(resp, new(Mat[float64])); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	if err := Unmarshal(resp, new(MaskedMat[int64])); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestUnmarshalPointers(t *testing.T) {
+	resp, err := Eval("[1, missing, 3]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var x []*int64
+	if err := Unmarshal(resp, &x); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x) != 3 || *x[0] != 1 || x[1] != nil || *x[2] != 3 {
+		t.Fatal("did not receive expected values", x)
+	}
+
+	// vectors without missing values have no nil elements
+	resp, err = Eval("[1.5, 2.5]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var y []*float64
+	if err := Unmarshal(resp, &y); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(y) != 2 || *y[0] != 1.5 || *y[1] != 2.5 {
+		t.Fatal("did not receive expected values", y)
+	}
+
+	if err := Unmarshal(resp, &x); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
@@ -0,0 +1,155 @@
+package julia
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Int128 is a signed 128-bit integer corresponding to julia Int128.
+// Its memory layout matches julia, i.e. low 64 bits followed by high 64 bits
+type Int128 struct {
+	Lo uint64
+	Hi int64
+}
+
+// UInt128 is an unsigned 128-bit integer corresponding to julia UInt128
+type UInt128 struct {
+	Lo uint64
+	Hi uint64
+}
+
+// Float16 is a half precision floating point number corresponding to
+// julia Float16. It holds IEEE 754 bit pattern of the number, which is
+// converted via NewFloat16 and Float32
+type Float16 uint16
+
+var (
+	minInt128  = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	maxInt128  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	maxUInt128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	maxUInt64  = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+// NewInt128 converts x to Int128 returning an error if it overflows
+func NewInt128(x *big.Int) (Int128, error) {
+	if x.Cmp(minInt128) < 0 || x.Cmp(maxInt128) > 0 {
+		return Int128{}, fmt.Errorf("%s overflows Int128", x)
+	}
+
+	// big.Int uses two's complement semantics for bitwise operations
+	u := new(big.Int).And(x, maxUInt128)
+	return Int128{
+		Lo: new(big.Int).And(u, maxUInt64).Uint64(),
+		Hi: int64(new(big.Int).Rsh(u, 64).Uint64()),
+	}, nil
+}
+
+// Big converts x to big.Int
+func (x Int128) Big() *big.Int {
+	b := big.NewInt(x.Hi)
+	b.Lsh(b, 64)
+	return b.Add(b, new(big.Int).SetUint64(x.Lo))
+}
+
+// String returns decimal representation of x
+func (x Int128) String() string {
+	return x.Big().String()
+}
+
+// NewUInt128 converts x to UInt128 returning an error if it overflows
+func NewUInt128(x *big.Int) (UInt128, error) {
+	if x.Sign() < 0 || x.Cmp(maxUInt128) > 0 {
+		return UInt128{}, fmt.Errorf("%s overflows UInt128", x)
+	}
+
+	return UInt128{
+		Lo: new(big.Int).And(x, maxUInt64).Uint64(),
+		Hi: new(big.Int).Rsh(x, 64).Uint64(),
+	}, nil
+}
+
+// Big converts x to big.Int
+func (x UInt128) Big() *big.Int {
+	b := new(big.Int).SetUint64(x.Hi)
+	b.Lsh(b, 64)
+	return b.Add(b, new(big.Int).SetUint64(x.Lo))
+}
+
+// String returns decimal representation of x
+func (x UInt128) String() string {
+	return x.Big().String()
+}
+
+// NewFloat16 converts f to half precision rounding to nearest even.
+// Values out of range of Float16 become infinite
+func NewFloat16(f float32) Float16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23) & 0xff
+	mant := b & 0x7fffff
+
+	// infinity or NaN, which remains a quiet NaN
+	if exp == 0xff {
+		if mant != 0 {
+			return Float16(sign | 0x7e00)
+		}
+		return Float16(sign | 0x7c00)
+	}
+
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return Float16(sign | 0x7c00)
+	}
+
+	// subnormal or zero
+	if e <= 0 {
+		if e < -10 {
+			return Float16(sign)
+		}
+
+		mant |= 0x800000
+		shift := uint(14 - e)
+		m := mant >> shift
+		rem, half := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > half || (rem == half && m&1 == 1) {
+			m++
+		}
+
+		return Float16(sign | uint16(m))
+	}
+
+	// carry of rounding propagates into exponent, which is correct
+	// including overflow to infinity
+	h := uint16(e)<<10 | uint16(mant>>13)
+	if rem := mant & 0x1fff; rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+		h++
+	}
+
+	return Float16(sign | h)
+}
+
+// Float32 converts h to float32, which is exact
+func (h Float16) Float32() float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+
+	switch exp {
+	case 0:
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	default:
+		return math.Float32frombits(sign | (exp+112)<<23 | mant<<13)
+	}
+}
+
+// String returns decimal representation of h
+func (h Float16) String() string {
+	return fmt.Sprint(h.Float32())
+}
@@ -0,0 +1,93 @@
+package julia
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestNumericInt128(t *testing.T) {
+	for _, s := range []string{
+		"0",
+		"-1",
+		"18446744073709551616",
+		"-170141183460469231731687303715884105728",
+		"170141183460469231731687303715884105727",
+	} {
+		b, _ := new(big.Int).SetString(s, 10)
+		x, err := NewInt128(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if x.String() != s {
+			t.Fatal("expected", s, "got", x)
+		}
+	}
+
+	if x, _ := NewInt128(big.NewInt(-1)); x.Lo != math.MaxUint64 || x.Hi != -1 {
+		t.Fatal("expected two's complement of -1, got", x.Lo, x.Hi)
+	}
+
+	b, _ := new(big.Int).SetString("170141183460469231731687303715884105728", 10)
+	if _, err := NewInt128(b); err == nil {
+		t.Fatal("expected overflow of Int128")
+	}
+}
+
+func TestNumericUInt128(t *testing.T) {
+	b, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	x, err := NewUInt128(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Lo != math.MaxUint64 || x.Hi != math.MaxUint64 || x.Big().Cmp(b) != 0 {
+		t.Fatal("expected max UInt128, got", x)
+	}
+
+	if _, err := NewUInt128(big.NewInt(-1)); err == nil {
+		t.Fatal("expected overflow of UInt128")
+	}
+}
+
+func TestNumericFloat16(t *testing.T) {
+	for _, tc := range []struct {
+		f    float32
+		bits Float16
+	}{
+		{f: 0, bits: 0x0000},
+		{f: 1, bits: 0x3c00},
+		{f: -2, bits: 0xc000},
+		{f: 0.5, bits: 0x3800},
+		{f: 65504, bits: 0x7bff},
+		{f: 6.1035156e-05, bits: 0x0400},
+		{f: 5.9604645e-08, bits: 0x0001},
+		{f: float32(math.Inf(1)), bits: 0x7c00},
+	} {
+		if h := NewFloat16(tc.f); h != tc.bits {
+			t.Fatalf("expected %#04x for %v, got %#04x", tc.bits, tc.f, h)
+		}
+
+		if f := tc.bits.Float32(); f != tc.f {
+			t.Fatal("expected", tc.f, "got", f)
+		}
+	}
+
+	// values are rounded to nearest even
+	if h := NewFloat16(1 + 1.0/2048); h != 0x3c00 {
+		t.Fatalf("expected 1 to be rounded down, got %#04x", h)
+	}
+
+	if h := NewFloat16(1 + 3.0/2048); h != 0x3c02 {
+		t.Fatalf("expected 1 to be rounded up, got %#04x", h)
+	}
+
+	if h := NewFloat16(65520); h != 0x7c00 {
+		t.Fatalf("expected overflow to infinity, got %#04x", h)
+	}
+
+	if f := NewFloat16(float32(math.NaN())).Float32(); !math.IsNaN(float64(f)) {
+		t.Fatal("expected NaN, got", f)
+	}
+}
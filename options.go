@@ -0,0 +1,172 @@
+package julia
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// OptLevel is the julia code optimization level, i.e. -O flag
+type OptLevel int
+
+const (
+	OptLevelDefault OptLevel = iota
+	OptLevel0
+	OptLevel1
+	OptLevel2
+	OptLevel3
+)
+
+// Switch represents a julia command line flag that can be turned on or off
+// and defaults to julia runtime behavior when unset
+type Switch int
+
+const (
+	SwitchDefault Switch = iota
+	SwitchOn
+	SwitchOff
+)
+
+// Options are applied to julia runtime before it starts. Zero value
+// of Options uses julia defaults and the julia install this package
+// was built against
+type Options struct {
+	// BinDir is the directory containing julia executable, which is used
+	// to locate the system image and the standard library. It defaults to
+	// the value of JULIA_BINDIR environment variable when Image is set
+	BinDir string
+
+	// Library is the path to libjulia shared library, which is loaded when
+	// the runtime is initialized if the package is built with julia_dlopen
+	// tag. It defaults to libjulia in lib directory next to BinDir or
+	// JULIA_BINDIR, falling back to the dynamic linker search path
+	Library string
+
+	// Image is the path to a custom system image. Relative paths are
+	// resolved against BinDir
+	Image string
+
+	// Threads is the number of julia threads, i.e. --threads flag
+	Threads int
+
+	// OptLevel is the optimization level, i.e. -O flag
+	OptLevel OptLevel
+
+	// CheckBounds turns bounds checking on or off, i.e. --check-bounds flag
+	CheckBounds Switch
+
+	// Project is the path to julia project environment, i.e. --project flag
+	Project string
+
+	// HandleSignals turns julia signal handlers on or off, i.e. --handle-signals flag
+	HandleSignals Switch
+
+	// AutoRelease releases values returned by the runtime once they are
+	// garbage collected by go, in addition to releasing them explicitly
+	// via Release. It has no effect on worker processes
+	AutoRelease bool
+}
+
+// args returns julia command line arguments corresponding to options
+func (o *Options) args() ([]string, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	var args []string
+
+	if o.Threads < 0 {
+		return nil, fmt.Errorf("invalid number of threads %d", o.Threads)
+	}
+	if o.Threads > 0 {
+		args = append(args, "--threads="+strconv.Itoa(o.Threads))
+	}
+
+	switch o.OptLevel {
+	case OptLevelDefault:
+	case OptLevel0, OptLevel1, OptLevel2, OptLevel3:
+		args = append(args, "-O"+strconv.Itoa(int(o.OptLevel-OptLevel0)))
+	default:
+		return nil, fmt.Errorf("invalid optimization level %d", o.OptLevel)
+	}
+
+	if arg, err := o.CheckBounds.arg("--check-bounds"); err != nil {
+		return nil, err
+	} else if len(arg) > 0 {
+		args = append(args, arg)
+	}
+
+	if len(o.Project) > 0 {
+		args = append(args, "--project="+o.Project)
+	}
+
+	if arg, err := o.HandleSignals.arg("--handle-signals"); err != nil {
+		return nil, err
+	} else if len(arg) > 0 {
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+// image returns julia bindir and system image path. Both are empty when
+// julia defaults are to be used
+func (o *Options) image() (string, string, error) {
+	if o == nil || (len(o.BinDir) == 0 && len(o.Image) == 0) {
+		return "", "", nil
+	}
+
+	binDir := o.BinDir
+	if len(binDir) == 0 {
+		binDir = os.Getenv("JULIA_BINDIR")
+	}
+
+	if len(binDir) == 0 {
+		return "", "", fmt.Errorf("julia bindir is required for custom image, set BinDir or JULIA_BINDIR")
+	}
+
+	return binDir, o.Image, nil
+}
+
+// library returns path to libjulia shared library
+func (o *Options) library() string {
+	var lib, binDir string
+	if o != nil {
+		lib, binDir = o.Library, o.BinDir
+	}
+
+	if len(lib) > 0 {
+		return lib
+	}
+
+	name := "libjulia.so"
+	if runtime.GOOS == "darwin" {
+		name = "libjulia.dylib"
+	}
+
+	if len(binDir) == 0 {
+		binDir = os.Getenv("JULIA_BINDIR")
+	}
+
+	if len(binDir) == 0 {
+		return name
+	}
+
+	return filepath.Join(binDir, "..", "lib", name)
+}
+
+// arg formats switch as a julia command line flag
+func (s Switch) arg(flag string) (string, error) {
+	switch s {
+	case SwitchDefault:
+		return "", nil
+	case SwitchOn:
+		return flag + "=yes", nil
+	case SwitchOff:
+		return flag + "=no", nil
+	default:
+		return "", fmt.Errorf("invalid value %d for %s", s, flag)
+	}
+}
@@ -0,0 +1,84 @@
+package julia
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptionsArgs(t *testing.T) {
+	opts := &Options{
+		Threads:       4,
+		OptLevel:      OptLevel3,
+		CheckBounds:   SwitchOff,
+		Project:       "/opt/app",
+		HandleSignals: SwitchOn,
+	}
+
+	args, err := opts.args()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "--threads=4 -O3 --check-bounds=no --project=/opt/app --handle-signals=yes"
+	if got := strings.Join(args, " "); got != expected {
+		t.Fatal("expected", expected, "got", got)
+	}
+
+	opts = &Options{}
+	if args, err := opts.args(); err != nil || len(args) != 0 {
+		t.Fatal("expected no args for zero value options, got", args, err)
+	}
+
+	opts = &Options{OptLevel: OptLevel0}
+	if args, err := opts.args(); err != nil || strings.Join(args, " ") != "-O0" {
+		t.Fatal("expected -O0, got", args, err)
+	}
+
+	opts = &Options{Threads: -1}
+	if _, err := opts.args(); err == nil {
+		t.Fatal("expected negative threads to fail")
+	}
+}
+
+func TestOptionsImage(t *testing.T) {
+	t.Setenv("JULIA_BINDIR", "")
+
+	opts := &Options{Image: "sys.so"}
+	if _, _, err := opts.image(); err == nil {
+		t.Fatal("expected custom image without bindir to fail")
+	}
+
+	t.Setenv("JULIA_BINDIR", "/opt/julia/bin")
+	binDir, image, err := opts.image()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if binDir != "/opt/julia/bin" || image != "sys.so" {
+		t.Fatal("expected bindir from environment, got", binDir, image)
+	}
+}
+
+func TestOptionsLibrary(t *testing.T) {
+	t.Setenv("JULIA_BINDIR", "")
+
+	var opts *Options
+	if lib := opts.library(); lib != "libjulia.so" && lib != "libjulia.dylib" {
+		t.Fatal("expected library from search path, got", lib)
+	}
+
+	t.Setenv("JULIA_BINDIR", "/opt/julia/bin")
+	if lib := opts.library(); !strings.HasPrefix(lib, "/opt/julia/lib/libjulia.") {
+		t.Fatal("expected library next to bindir from environment, got", lib)
+	}
+
+	opts = &Options{BinDir: "/usr/local/julia-1.9/bin"}
+	if lib := opts.library(); !strings.HasPrefix(lib, "/usr/local/julia-1.9/lib/libjulia.") {
+		t.Fatal("expected library next to bindir, got", lib)
+	}
+
+	opts = &Options{BinDir: "/usr/local/julia-1.9/bin", Library: "/opt/libjulia.so.1"}
+	if lib := opts.library(); lib != "/opt/libjulia.so.1" {
+		t.Fatal("expected explicit library, got", lib)
+	}
+}
@@ -0,0 +1,261 @@
+package julia
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// PoolOptions configure a pool of julia worker processes
+type PoolOptions struct {
+	// Size is the number of worker processes, defaults to number of CPUs
+	Size int
+
+	// Preload is julia code evaluated on each worker process when it
+	// starts, including restarts, such as loading packages and declaring
+	// functions
+	Preload []string
+
+	// MaxRequests recycles a worker process once it has served this many
+	// requests. Zero means worker processes are never recycled
+	MaxRequests int
+
+	// HealthCheckInterval is how often idle workers are pinged and
+	// restarted if they do not respond within the interval. Zero disables
+	// health checks
+	HealthCheckInterval time.Duration
+
+	// Options are applied to each worker process
+	Options *Options
+}
+
+// WorkerPool dispatches work to a pool of julia worker processes so that
+// julia code runs in parallel. Each call to Do is routed to the least
+// busy worker.
+type WorkerPool struct {
+	opts    PoolOptions
+	workers []*poolWorker
+
+	mu       sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// poolWorker tracks number of in-flight calls dispatched to a worker
+type poolWorker struct {
+	*Worker
+	busy int
+}
+
+// NewWorkerPool creates a pool and starts its worker processes, running
+// preload code on each of them
+func NewWorkerPool(opts PoolOptions) (*WorkerPool, error) {
+	if opts.Size <= 0 {
+		opts.Size = runtime.NumCPU()
+	}
+
+	p := &WorkerPool{
+		opts:    opts,
+		workers: make([]*poolWorker, opts.Size),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	for i := range p.workers {
+		p.workers[i] = &poolWorker{
+			Worker: &Worker{
+				opts:    opts.Options,
+				preload: opts.Preload,
+			},
+		}
+	}
+
+	errC := make(chan error, len(p.workers))
+	for _, w := range p.workers {
+		go func(w *poolWorker) {
+			errC <- w.Start()
+		}(w)
+	}
+
+	var err error
+	for range p.workers {
+		if e := <-errC; e != nil && err == nil {
+			err = e
+		}
+	}
+
+	if err != nil {
+		for _, w := range p.workers {
+			_ = w.Close()
+		}
+		return nil, err
+	}
+
+	go p.healthCheck()
+
+	return p, nil
+}
+
+// Do runs f with the least busy worker. Values created via the worker
+// belong to it and should not be used outside of f
+func (p *WorkerPool) Do(ctx context.Context, f func(w *Worker) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	w, err := p.acquire()
+	if err != nil {
+		return err
+	}
+	defer p.release(w)
+
+	return f(w.Worker)
+}
+
+// Close waits for in-flight calls to complete and stops worker processes
+func (p *WorkerPool) Close() error {
+	return p.Shutdown(context.Background())
+}
+
+// Shutdown stops accepting new calls and waits for in-flight calls to
+// complete or ctx to be done before stopping worker processes. Worker
+// processes are killed once ctx is done, failing calls still in flight
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	close(p.stop)
+	<-p.done
+
+	drained := make(chan struct{})
+	go func() {
+		p.inflight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+
+		// calls in flight hold their workers until the processes are
+		// killed, which Close would otherwise wait for
+		for _, w := range p.workers {
+			w.kill()
+		}
+	}
+
+	for _, w := range p.workers {
+		if e := w.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// acquire picks the least busy worker
+func (p *WorkerPool) acquire() (*poolWorker, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+
+	w := p.workers[0]
+	for _, worker := range p.workers[1:] {
+		if worker.busy < w.busy {
+			w = worker
+		}
+	}
+
+	w.busy++
+	p.inflight.Add(1)
+
+	return w, nil
+}
+
+// release returns worker to the pool, recycling its process once it
+// has served max requests and is no longer busy
+func (p *WorkerPool) release(w *poolWorker) {
+	defer p.inflight.Done()
+
+	p.mu.Lock()
+	w.busy--
+	recycle := w.busy == 0 &&
+		p.opts.MaxRequests > 0 &&
+		w.Requests() >= p.opts.MaxRequests
+	if recycle {
+		// keep worker busy while it is being recycled so that it is
+		// not picked by other calls
+		w.busy++
+	}
+	p.mu.Unlock()
+
+	if !recycle {
+		return
+	}
+
+	_ = w.recycle()
+	_ = w.Start()
+
+	p.mu.Lock()
+	w.busy--
+	p.mu.Unlock()
+}
+
+// healthCheck periodically pings idle workers and restarts those that
+// do not respond
+func (p *WorkerPool) healthCheck() {
+	defer close(p.done)
+
+	if p.opts.HealthCheckInterval <= 0 {
+		<-p.stop
+		return
+	}
+
+	ticker := time.NewTicker(p.opts.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		for _, w := range p.workers {
+			p.mu.Lock()
+			idle := w.busy == 0
+			if idle {
+				w.busy++
+			}
+			p.mu.Unlock()
+
+			if !idle {
+				continue
+			}
+
+			// a ping that is not answered until the next check is due
+			// is taken for a hung worker process
+			if err := w.ping(p.opts.HealthCheckInterval); err != nil {
+				_ = w.recycle()
+				_ = w.Start()
+			}
+
+			p.mu.Lock()
+			w.busy--
+			p.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package julia
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestPool returns a pool or skips the test if julia executable
+// is not available
+func newTestPool(t *testing.T, opts PoolOptions) *WorkerPool {
+	if _, err := exec.LookPath("julia"); err != nil {
+		t.Skip("julia executable not found")
+	}
+
+	p, err := NewWorkerPool(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = p.Close()
+	})
+
+	return p
+}
+
+func TestWorkerPoolParallel(t *testing.T) {
+	p := newTestPool(t, PoolOptions{
+		Size:    2,
+		Preload: []string{"square(x) = x * x"},
+	})
+
+	var wg sync.WaitGroup
+	errC := make(chan error, 8)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			errC <- p.Do(context.Background(), func(w *Worker) error {
+				arg, err := w.Marshal(int64(i))
+				if err != nil {
+					return err
+				}
+
+				resp, err := w.EvalFunc("square", ModuleMain, arg)
+				if err != nil {
+					return err
+				}
+
+				var n int64
+				if err := w.Unmarshal(resp, &n); err != nil {
+					return err
+				}
+
+				if n != int64(i*i) {
+					return errors.New("did not receive expected value")
+				}
+
+				return nil
+			})
+		}(i)
+	}
+
+	wg.Wait()
+	close(errC)
+
+	for err := range errC {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWorkerPoolRecycle(t *testing.T) {
+	p := newTestPool(t, PoolOptions{
+		Size:        1,
+		MaxRequests: 2,
+		Preload:     []string{"square(x) = x * x"},
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := p.Do(context.Background(), func(w *Worker) error {
+			_, err := w.Eval("square(2)")
+			if err != nil {
+				return err
+			}
+
+			_, err = w.Eval("square(3)")
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if n := p.workers[0].Requests(); n != 0 {
+			t.Fatal("expected worker process to be recycled, got requests", n)
+		}
+	}
+}
+
+func TestWorkerPoolClosed(t *testing.T) {
+	p := newTestPool(t, PoolOptions{Size: 1})
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Do(context.Background(), func(w *Worker) error {
+		return nil
+	}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatal("expected ErrPoolClosed, got", err)
+	}
+}
+
+func TestWorkerPoolHealthCheckHungWorker(t *testing.T) {
+	p := newTestPool(t, PoolOptions{
+		Size:                1,
+		HealthCheckInterval: 500 * time.Millisecond,
+	})
+
+	// the task blocks worker process once it is idle, since it never yields
+	if err := p.Do(context.Background(), func(w *Worker) error {
+		_, err := w.Eval("@async (sleep(0.1); while true end)")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := p.workers[0]
+	w.mu.Lock()
+	gen := w.gen
+	w.mu.Unlock()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		w.mu.Lock()
+		restarted := w.gen != gen
+		w.mu.Unlock()
+
+		if restarted {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("expected hung worker process to be restarted")
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err := p.Do(context.Background(), func(w *Worker) error {
+		_, err := w.Eval("1 + 1")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkerPoolShutdownHungCall(t *testing.T) {
+	p := newTestPool(t, PoolOptions{Size: 1})
+
+	// worker process is started before the call blocks it
+	if err := p.Do(context.Background(), func(w *Worker) error {
+		return w.Start()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	errC := make(chan error, 1)
+	go func() {
+		errC <- p.Do(context.Background(), func(w *Worker) error {
+			close(started)
+			_, err := w.Eval("while true end")
+			return err
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected context.DeadlineExceeded, got", err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("expected shutdown to return shortly after deadline, took", time.Since(start))
+	}
+
+	if err := <-errC; err == nil {
+		t.Fatal("expected call in flight to fail once worker process is killed")
+	}
+}
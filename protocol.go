@@ -0,0 +1,511 @@
+package julia
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Worker protocol exchanges length-prefixed frames, each consisting of a
+// little endian uint32 length followed by the payload. Request payload
+// starts with an op code, whereas response payload starts with a status.
+// Strings are encoded as uint32 length followed by bytes and values are
+// referred to by uint64 handles owned by julia worker.
+const (
+	opEval byte = iota + 1
+	opCall
+	opPut
+	opGet
+	opType
+	opRelease
+	opPing
+)
+
+const (
+	statusOK byte = iota
+	statusError
+)
+
+// kinds identify element types of values exchanged with worker.
+// These must be kept in sync with worker.jl
+const (
+	kindBool byte = iota + 1
+	kindUint8
+	kindUint16
+	kindUint32
+	kindUint64
+	kindInt8
+	kindInt16
+	kindInt32
+	kindInt64
+	kindFloat32
+	kindFloat64
+	kindComplex64
+	kindComplex128
+	kindInt128
+	kindUInt128
+	kindFloat16
+
+	// kindString encodes strings as uint32 length followed by bytes,
+	// whereas arrays of strings are encoded as such strings in column
+	// major order
+	kindString
+)
+
+// maxFrameSize limits the size of a single frame to guard against
+// reading garbage as frame length
+const maxFrameSize = 1 << 31
+
+// writeFrame writes payload prefixed with its length
+func writeFrame(w io.Writer, payload []byte) error {
+	if len(payload) >= maxFrameSize {
+		return fmt.Errorf("frame too large: %d bytes", len(payload))
+	}
+
+	header := make([]byte, 4)
+	binary.LittleEndian.PutUint32(header, uint32(len(payload)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads a length-prefixed payload
+func readFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	n := binary.LittleEndian.Uint32(header)
+	if n >= maxFrameSize {
+		return nil, fmt.Errorf("frame too large: %d bytes", n)
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// encoder builds request payloads
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) writeString(s string) {
+	e.writeUint32(uint32(len(s)))
+	e.WriteString(s)
+}
+
+func (e *encoder) writeUint32(n uint32) {
+	_ = binary.Write(e, binary.LittleEndian, n)
+}
+
+func (e *encoder) writeUint64(n uint64) {
+	_ = binary.Write(e, binary.LittleEndian, n)
+}
+
+// writeValue encodes x as kind, rank, dims and little endian data.
+// Scalars have rank 0. Supported types are primitive types, strings,
+// their slices and Mat
+func (e *encoder) writeValue(x any) error {
+	switch v := x.(type) {
+	case string:
+		e.WriteByte(kindString)
+		e.WriteByte(0)
+		e.writeString(v)
+		return nil
+	case []string:
+		e.WriteByte(kindString)
+		e.WriteByte(1)
+		e.writeUint64(uint64(len(v)))
+		for _, s := range v {
+			e.writeString(s)
+		}
+		return nil
+	case bool, uint8, uint16, uint32, uint64, int8, int16, int32, int64, float32, float64, complex64, complex128, Int128, UInt128, Float16:
+		kind, err := kindOf(v)
+		if err != nil {
+			return err
+		}
+		e.WriteByte(kind)
+		e.WriteByte(0)
+		return binary.Write(e, binary.LittleEndian, v)
+	case []bool:
+		return writeMat(e, &Mat[bool]{elms: v, dims: []int{len(v)}})
+	case []uint8:
+		return writeMat(e, &Mat[uint8]{elms: v, dims: []int{len(v)}})
+	case []uint16:
+		return writeMat(e, &Mat[uint16]{elms: v, dims: []int{len(v)}})
+	case []uint32:
+		return writeMat(e, &Mat[uint32]{elms: v, dims: []int{len(v)}})
+	case []uint64:
+		return writeMat(e, &Mat[uint64]{elms: v, dims: []int{len(v)}})
+	case []int8:
+		return writeMat(e, &Mat[int8]{elms: v, dims: []int{len(v)}})
+	case []int16:
+		return writeMat(e, &Mat[int16]{elms: v, dims: []int{len(v)}})
+	case []int32:
+		return writeMat(e, &Mat[int32]{elms: v, dims: []int{len(v)}})
+	case []int64:
+		return writeMat(e, &Mat[int64]{elms: v, dims: []int{len(v)}})
+	case []float32:
+		return writeMat(e, &Mat[float32]{elms: v, dims: []int{len(v)}})
+	case []float64:
+		return writeMat(e, &Mat[float64]{elms: v, dims: []int{len(v)}})
+	case []complex64:
+		return writeMat(e, &Mat[complex64]{elms: v, dims: []int{len(v)}})
+	case []complex128:
+		return writeMat(e, &Mat[complex128]{elms: v, dims: []int{len(v)}})
+	case []Int128:
+		return writeMat(e, &Mat[Int128]{elms: v, dims: []int{len(v)}})
+	case []UInt128:
+		return writeMat(e, &Mat[UInt128]{elms: v, dims: []int{len(v)}})
+	case []Float16:
+		return writeMat(e, &Mat[Float16]{elms: v, dims: []int{len(v)}})
+	case *Mat[bool]:
+		return writeMat(e, v)
+	case *Mat[uint8]:
+		return writeMat(e, v)
+	case *Mat[uint16]:
+		return writeMat(e, v)
+	case *Mat[uint32]:
+		return writeMat(e, v)
+	case *Mat[uint64]:
+		return writeMat(e, v)
+	case *Mat[int8]:
+		return writeMat(e, v)
+	case *Mat[int16]:
+		return writeMat(e, v)
+	case *Mat[int32]:
+		return writeMat(e, v)
+	case *Mat[int64]:
+		return writeMat(e, v)
+	case *Mat[float32]:
+		return writeMat(e, v)
+	case *Mat[float64]:
+		return writeMat(e, v)
+	case *Mat[complex64]:
+		return writeMat(e, v)
+	case *Mat[complex128]:
+		return writeMat(e, v)
+	case *Mat[Int128]:
+		return writeMat(e, v)
+	case *Mat[UInt128]:
+		return writeMat(e, v)
+	case *Mat[Float16]:
+		return writeMat(e, v)
+	default:
+		return fmt.Errorf("invalid type, not supported %T", v)
+	}
+}
+
+// writeMat encodes matrix. bool matrices are encoded as Int8 arrays
+// to match the embedded runtime
+func writeMat[T PrimitiveTypes](e *encoder, v *Mat[T]) error {
+	var el T
+	kind, err := kindOf(any(el))
+	if err != nil {
+		return err
+	}
+	if kind == kindBool {
+		kind = kindInt8
+	}
+
+	if len(v.dims) > 255 {
+		return fmt.Errorf("invalid dims, rank %d is too large", len(v.dims))
+	}
+
+	e.WriteByte(kind)
+	e.WriteByte(byte(len(v.dims)))
+	for _, dim := range v.dims {
+		e.writeUint64(uint64(dim))
+	}
+
+	return binary.Write(e, binary.LittleEndian, v.elms)
+}
+
+// decoder reads response payloads
+type decoder struct {
+	*bytes.Reader
+}
+
+func newDecoder(payload []byte) *decoder {
+	return &decoder{Reader: bytes.NewReader(payload)}
+}
+
+func (d *decoder) readString() (string, error) {
+	n, err := d.readUint32()
+	if err != nil {
+		return "", err
+	}
+
+	if int64(n) > int64(d.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d, b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func (d *decoder) readUint32() (uint32, error) {
+	var n uint32
+	err := binary.Read(d, binary.LittleEndian, &n)
+	return n, err
+}
+
+func (d *decoder) readUint64() (uint64, error) {
+	var n uint64
+	err := binary.Read(d, binary.LittleEndian, &n)
+	return n, err
+}
+
+// readError decodes exception type, message and backtrace
+func (d *decoder) readError() error {
+	var err error
+	jErr := &JuliaError{}
+
+	if jErr.Type, err = d.readString(); err != nil {
+		return err
+	}
+	if jErr.Message, err = d.readString(); err != nil {
+		return err
+	}
+	if jErr.Backtrace, err = d.readString(); err != nil {
+		return err
+	}
+
+	return jErr
+}
+
+// readValue decodes value header, i.e. kind and dims, leaving data
+// to be read into the target by readInto
+func (d *decoder) readValue() (*encodedValue, error) {
+	kind, err := d.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	rank, err := d.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	dims := make([]int, rank)
+	for i := range dims {
+		dim, err := d.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		dims[i] = int(dim)
+	}
+
+	return &encodedValue{kind: kind, dims: dims, data: d}, nil
+}
+
+// encodedValue is a decoded value header along with its undecoded data
+type encodedValue struct {
+	typeName string
+	kind     byte
+	dims     []int
+	data     *decoder
+}
+
+// readInto decodes value data into x, which is a pointer to primitive type,
+// string, slice of strings or a Mat, after checking its type and shape
+func (v *encodedValue) readInto(x any) error {
+	switch p := x.(type) {
+	case *string:
+		if len(v.dims) != 0 || v.kind != kindString {
+			return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, v.typeName, x)
+		}
+
+		s, err := v.data.readString()
+		if err != nil {
+			return err
+		}
+
+		*p = s
+		return nil
+	case *[]string:
+		if len(v.dims) != 1 || v.kind != kindString {
+			return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, v.typeName, x)
+		}
+
+		out := make([]string, v.dims[0])
+		for i := range out {
+			s, err := v.data.readString()
+			if err != nil {
+				return err
+			}
+			out[i] = s
+		}
+
+		*p = out
+		return nil
+	case *bool, *uint8, *uint16, *uint32, *uint64, *int8, *int16, *int32, *int64, *float32, *float64, *complex64, *complex128, *Int128, *UInt128, *Float16:
+		kind, err := kindOf(deref(p))
+		if err != nil {
+			return err
+		}
+		if len(v.dims) != 0 || v.kind != kind {
+			return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, v.typeName, x)
+		}
+		return binary.Read(v.data, binary.LittleEndian, p)
+	case *Mat[bool]:
+		return readMat(v, p)
+	case *Mat[uint8]:
+		return readMat(v, p)
+	case *Mat[uint16]:
+		return readMat(v, p)
+	case *Mat[uint32]:
+		return readMat(v, p)
+	case *Mat[uint64]:
+		return readMat(v, p)
+	case *Mat[int8]:
+		return readMat(v, p)
+	case *Mat[int16]:
+		return readMat(v, p)
+	case *Mat[int32]:
+		return readMat(v, p)
+	case *Mat[int64]:
+		return readMat(v, p)
+	case *Mat[float32]:
+		return readMat(v, p)
+	case *Mat[float64]:
+		return readMat(v, p)
+	case *Mat[complex64]:
+		return readMat(v, p)
+	case *Mat[complex128]:
+		return readMat(v, p)
+	case *Mat[Int128]:
+		return readMat(v, p)
+	case *Mat[UInt128]:
+		return readMat(v, p)
+	case *Mat[Float16]:
+		return readMat(v, p)
+	default:
+		return fmt.Errorf("invalid type, not supported %T", x)
+	}
+}
+
+// readMat decodes array data into matrix, populating an empty matrix or
+// checking shape of a preallocated one
+func readMat[T PrimitiveTypes](v *encodedValue, m *Mat[T]) error {
+	var el T
+	kind, err := kindOf(any(el))
+	if err != nil {
+		return err
+	}
+
+	// bool matrices accept both Int8 and Bool arrays
+	ok := v.kind == kind || (kind == kindBool && v.kind == kindInt8)
+	if len(v.dims) == 0 || !ok || (len(m.dims) > 0 && len(m.dims) != len(v.dims)) {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, v.typeName, m)
+	}
+
+	if len(m.dims) == 0 {
+		numElements := 1
+		for _, dim := range v.dims {
+			numElements *= dim
+		}
+		m.dims = v.dims
+		m.elms = make([]T, numElements)
+	} else if !equalDims(m.dims, v.dims) {
+		return fmt.Errorf("%w: cannot unmarshal julia array of dims %v into mat of dims %v",
+			ErrShapeMismatch, v.dims, m.dims)
+	}
+
+	return binary.Read(v.data, binary.LittleEndian, m.elms)
+}
+
+// kindOf returns kind of go primitive type of el
+func kindOf(el any) (byte, error) {
+	switch el.(type) {
+	case bool:
+		return kindBool, nil
+	case uint8:
+		return kindUint8, nil
+	case uint16:
+		return kindUint16, nil
+	case uint32:
+		return kindUint32, nil
+	case uint64:
+		return kindUint64, nil
+	case int8:
+		return kindInt8, nil
+	case int16:
+		return kindInt16, nil
+	case int32:
+		return kindInt32, nil
+	case int64:
+		return kindInt64, nil
+	case float32:
+		return kindFloat32, nil
+	case float64:
+		return kindFloat64, nil
+	case complex64:
+		return kindComplex64, nil
+	case complex128:
+		return kindComplex128, nil
+	case Int128:
+		return kindInt128, nil
+	case UInt128:
+		return kindUInt128, nil
+	case Float16:
+		return kindFloat16, nil
+	default:
+		return 0, fmt.Errorf("invalid type, not supported %T", el)
+	}
+}
+
+// deref returns value pointed to by pointer to primitive type p
+func deref(p any) any {
+	switch v := p.(type) {
+	case *bool:
+		return *v
+	case *uint8:
+		return *v
+	case *uint16:
+		return *v
+	case *uint32:
+		return *v
+	case *uint64:
+		return *v
+	case *int8:
+		return *v
+	case *int16:
+		return *v
+	case *int32:
+		return *v
+	case *int64:
+		return *v
+	case *float32:
+		return *v
+	case *float64:
+		return *v
+	case *complex64:
+		return *v
+	case *complex128:
+		return *v
+	case *Int128:
+		return *v
+	case *UInt128:
+		return *v
+	case *Float16:
+		return *v
+	default:
+		return nil
+	}
+}
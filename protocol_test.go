@@ -0,0 +1,137 @@
+package julia
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestProtocolFrame(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, []byte("abcd")); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := readFrame(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(payload) != "abcd" {
+		t.Fatal("expected abcd, got", string(payload))
+	}
+}
+
+func TestProtocolValueRoundTrip(t *testing.T) {
+	x, err := NewMat([]float64{1, 2, 3, 4, 5, 6}, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &encoder{}
+	if err := e.writeValue(x); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := newDecoder(e.Bytes()).readValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y := new(Mat[float64])
+	if err := value.readInto(y); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalDims(y.GetDims(), []int{2, 3}) {
+		t.Fatal("expected dims [2 3], got", y.GetDims())
+	}
+
+	for i := range x.GetElms() {
+		if x.GetElms()[i] != y.GetElms()[i] {
+			t.Fatal("expected", x.GetElms(), "got", y.GetElms())
+		}
+	}
+}
+
+func TestProtocolValueTypeMismatch(t *testing.T) {
+	e := &encoder{}
+	if err := e.writeValue(int64(7)); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := newDecoder(e.Bytes()).readValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	value.typeName = "Int64"
+
+	var f float64
+	if err := value.readInto(&f); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	var n int64
+	if err := value.readInto(&n); err != nil || n != 7 {
+		t.Fatal("expected 7, got", n, err)
+	}
+}
+
+func TestProtocolComplexRoundTrip(t *testing.T) {
+	e := &encoder{}
+	if err := e.writeValue(complex(1, -2)); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := newDecoder(e.Bytes()).readValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value.kind != kindComplex128 {
+		t.Fatal("expected complex kind, got", value.kind)
+	}
+
+	var z complex128
+	if err := value.readInto(&z); err != nil || z != complex(1, -2) {
+		t.Fatal("expected 1-2i, got", z, err)
+	}
+}
+
+func TestProtocolStringRoundTrip(t *testing.T) {
+	e := &encoder{}
+	if err := e.writeValue("abcd"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.writeValue([]string{"a", "", "αβ"}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDecoder(e.Bytes())
+	value, err := d.readValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s string
+	if err := value.readInto(&s); err != nil || s != "abcd" {
+		t.Fatal("expected abcd, got", s, err)
+	}
+
+	value, err = d.readValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	value.typeName = "Vector{String}"
+
+	var n float64
+	if err := value.readInto(&n); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	var list []string
+	if err := value.readInto(&list); err != nil || len(list) != 3 || list[2] != "αβ" {
+		t.Fatal("expected [a  αβ], got", list, err)
+	}
+}
@@ -0,0 +1,409 @@
+package julia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type runtimeState int
+
+// interruptGracePeriod is how long a canceled call waits for julia code
+// to acknowledge the interrupt before returning to the caller
+const interruptGracePeriod = 100 * time.Millisecond
+
+const (
+	stateNew runtimeState = iota
+	stateInitialized
+	stateFinalized
+)
+
+// defaultRuntime is used by package level functions unless
+// another runtime has been initialized
+var defaultRuntime = New(nil)
+
+// process tracks julia runtime state for the whole process since julia can
+// only be initialized once and cannot be initialized again after finalizing
+var process struct {
+	mu        sync.Mutex
+	owner     *Runtime
+	finalized bool
+
+	// started is set once julia is initialized, after which initializing
+	// again crashes the process. initErr is the error initialization
+	// failed with after julia was started, if any
+	started bool
+	initErr error
+}
+
+// Runtime is a handle to julia runtime embedded in this process. It tracks
+// the lifecycle of julia runtime, so that methods return ErrNotInitialized
+// or ErrFinalized instead of crashing when called out of order.
+//
+// All julia calls made via a runtime, including those made via package
+// level functions, are executed on a single OS thread, making it safe
+// to use the runtime from multiple goroutines.
+//
+// Only one runtime can be initialized per process.
+type Runtime struct {
+	mu    sync.Mutex
+	state runtimeState
+	opts  *Options
+	exec  *executor
+
+	// pending are values garbage collected by go, which are released
+	// by the executor before running next call
+	pendingMu sync.Mutex
+	pending   []*Value
+}
+
+// New creates a new runtime handle with options, which may be nil to
+// use julia defaults. The runtime needs to be initialized before use.
+// Once initialized, package level functions forward to this runtime
+func New(opts *Options) *Runtime {
+	return &Runtime{opts: opts}
+}
+
+// current returns the runtime initialized in this process, if any,
+// or the default runtime
+func current() *Runtime {
+	process.mu.Lock()
+	defer process.mu.Unlock()
+
+	if process.owner != nil {
+		return process.owner
+	}
+
+	return defaultRuntime
+}
+
+// Initialize initializes julia runtime. It is a no-op if the runtime
+// is already initialized
+func (r *Runtime) Initialize() error {
+	return r.executor().do(func() error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		switch r.state {
+		case stateInitialized:
+			return nil
+		case stateFinalized:
+			return ErrFinalized
+		}
+
+		process.mu.Lock()
+		defer process.mu.Unlock()
+
+		if process.finalized {
+			return ErrFinalized
+		}
+
+		if process.owner != nil {
+			return ErrAlreadyInitialized
+		}
+
+		// julia cannot be initialized again once started, even if
+		// initialization failed afterwards
+		if process.started {
+			return process.initErr
+		}
+
+		if err := initialize(r.opts); err != nil {
+			if process.started {
+				process.initErr = err
+			}
+			return err
+		}
+
+		process.owner = r
+		r.state = stateInitialized
+
+		return nil
+	})
+}
+
+// Finalize notifies julia runtime that the program is about to terminate.
+// Runtime cannot be used once finalized
+func (r *Runtime) Finalize() error {
+	return r.do(func() error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		process.mu.Lock()
+		defer process.mu.Unlock()
+
+		finalize()
+		process.finalized = true
+		r.state = stateFinalized
+
+		return nil
+	})
+}
+
+// Version returns version of julia runtime, such as 1.10.4
+func (r *Runtime) Version() (string, error) {
+	var v string
+	err := r.do(func() error {
+		v = version()
+		return nil
+	})
+
+	return v, err
+}
+
+// Eval evaluates input as if it were julia code. An exception thrown
+// by julia runtime is returned as *JuliaError
+func (r *Runtime) Eval(input string) (*Value, error) {
+	var value *Value
+	err := r.do(func() (err error) {
+		value, err = r.keep(eval(input))
+		return err
+	})
+
+	return value, err
+}
+
+// EvalFunc evaluates a function literal, represented by name and module it is defined in,
+// and passes any optional arguments to it, returning any output from julia runtime.
+// An exception thrown by julia runtime is returned as *JuliaError
+func (r *Runtime) EvalFunc(name string, moduleType ModuleType, args ...*Value) (*Value, error) {
+	var value *Value
+	err := r.do(func() (err error) {
+		value, err = r.keep(evalFunc(name, moduleType, args...))
+		return err
+	})
+
+	return value, err
+}
+
+// EvalContext is like Eval, however, it returns when ctx is done. Julia code
+// still running at that point is interrupted by an InterruptException and
+// ctx.Err() is returned, wrapped with julia error, if any. Result of julia
+// code that completed before the interrupt took effect is returned as is.
+//
+// Interrupting julia code requires julia signal handlers, which are on by
+// default, see Options.HandleSignals, and is supported on linux only.
+// Otherwise, cancellation only returns early, whereas julia code keeps
+// running. Julia code that does not reach a safepoint keeps running and
+// blocks subsequent calls until it completes
+func (r *Runtime) EvalContext(ctx context.Context, input string) (*Value, error) {
+	var value *Value
+	err := r.doContext(ctx, func() (err error) {
+		value, err = r.keepContext(ctx, func() (*Value, error) {
+			return eval(input)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// EvalFuncContext is like EvalFunc, however, it returns when ctx is done.
+// See EvalContext for details on interrupting julia code
+func (r *Runtime) EvalFuncContext(ctx context.Context, name string, moduleType ModuleType, args ...*Value) (*Value, error) {
+	var value *Value
+	err := r.doContext(ctx, func() (err error) {
+		value, err = r.keepContext(ctx, func() (*Value, error) {
+			return evalFunc(name, moduleType, args...)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Marshal packs x into a value that can be passed to julia runtime.
+// See package level Marshal for supported types. nil is marshaled to
+// julia nothing, structs to NamedTuple and maps to Dict, see MarshalStruct
+// and MarshalMap
+func (r *Runtime) Marshal(x any) (*Value, error) {
+	var value *Value
+	err := r.do(func() (err error) {
+		value, err = r.keep(marshal(x))
+		return err
+	})
+
+	return value, err
+}
+
+// Unmarshal unpacks julia value into x.
+// See package level Unmarshal for supported types. nil x only accepts
+// julia nothing, whereas struct and map pointers accept julia structs and
+// dicts, see UnmarshalStruct and UnmarshalMap
+func (r *Runtime) Unmarshal(data *Value, x any) error {
+	return r.do(func() error {
+		return unmarshal(data, x)
+	})
+}
+
+// UnmarshalTuple unpacks elements of julia tuple into x.
+// See package level UnmarshalTuple
+func (r *Runtime) UnmarshalTuple(data *Value, x ...any) error {
+	return r.do(func() error {
+		return unmarshalTuple(data, x...)
+	})
+}
+
+// do runs f on the executor thread if the runtime is in initialized state
+func (r *Runtime) do(f func() error) error {
+	return r.doContext(context.Background(), f)
+}
+
+// doContext runs f on the executor thread if the runtime is in initialized
+// state and interrupts it when ctx is done
+func (r *Runtime) doContext(ctx context.Context, f func() error) error {
+	r.mu.Lock()
+	err := r.checkState()
+	exec := r.exec
+	r.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	id, errC, err := exec.submit(ctx, func() error {
+		// state may have changed or ctx may be done while the
+		// request was queued
+		r.mu.Lock()
+		err := r.checkState()
+		r.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.releasePending()
+
+		return f()
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err := <-errC:
+		return err
+	case <-ctx.Done():
+	}
+
+	exec.interrupt(id)
+
+	timer := time.NewTimer(interruptGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case err := <-errC:
+		// f completed without observing ctx, hence its result is kept
+		// and returned rather than leaked
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, ctx.Err()) {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %v", ctx.Err(), err)
+	case <-timer.C:
+		return ctx.Err()
+	}
+}
+
+// executor returns the executor of the runtime, starting it if needed
+func (r *Runtime) executor() *executor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exec == nil {
+		r.exec = newExecutor()
+	}
+
+	return r.exec
+}
+
+// checkState returns an error if runtime is not in initialized state
+func (r *Runtime) checkState() error {
+	switch r.state {
+	case stateNew:
+		return ErrNotInitialized
+	case stateFinalized:
+		return ErrFinalized
+	default:
+		return nil
+	}
+}
+
+// keep roots value created on the executor thread and associates it
+// with the runtime. Values are released once garbage collected by go
+// if Options.AutoRelease is set
+func (r *Runtime) keep(value *Value, err error) (*Value, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if err := root(value); err != nil {
+		return nil, err
+	}
+
+	value.rt = r
+
+	if r.opts != nil && r.opts.AutoRelease {
+		runtime.SetFinalizer(value, r.releaseLater)
+	}
+
+	return value, nil
+}
+
+// keepContext keeps value returned by f like keep, however, value is
+// released if ctx is done, since the caller of doContext may have
+// returned without it by then
+func (r *Runtime) keepContext(ctx context.Context, f func() (*Value, error)) (*Value, error) {
+	value, err := r.keep(f())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		_ = release(value)
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// releaseLater queues value for release by the executor. It is called by
+// go garbage collector, which must not block on julia calls
+func (r *Runtime) releaseLater(value *Value) {
+	r.pendingMu.Lock()
+	defer r.pendingMu.Unlock()
+
+	r.pending = append(r.pending, value)
+}
+
+// releasePending releases values queued by releaseLater. It must be
+// called on the executor thread
+func (r *Runtime) releasePending() {
+	r.pendingMu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.pendingMu.Unlock()
+
+	for _, value := range pending {
+		_ = release(value)
+	}
+}
@@ -0,0 +1,299 @@
+package julia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRuntimeRepeatedInitialize(t *testing.T) {
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Eval("1 + 1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuntimeAlreadyInitialized(t *testing.T) {
+	r := New(nil)
+	if err := r.Initialize(); !errors.Is(err, ErrAlreadyInitialized) {
+		t.Fatal("expected ErrAlreadyInitialized, got", err)
+	}
+}
+
+func TestRuntimeNotInitialized(t *testing.T) {
+	r := New(nil)
+
+	if _, err := r.Eval("1 + 1"); !errors.Is(err, ErrNotInitialized) {
+		t.Fatal("expected ErrNotInitialized, got", err)
+	}
+
+	if _, err := r.Marshal(int64(1)); !errors.Is(err, ErrNotInitialized) {
+		t.Fatal("expected ErrNotInitialized, got", err)
+	}
+
+	if err := r.Finalize(); !errors.Is(err, ErrNotInitialized) {
+		t.Fatal("expected ErrNotInitialized, got", err)
+	}
+}
+
+func TestRuntimeFinalized(t *testing.T) {
+	r := &Runtime{state: stateFinalized}
+
+	if err := r.Initialize(); !errors.Is(err, ErrFinalized) {
+		t.Fatal("expected ErrFinalized, got", err)
+	}
+
+	if _, err := r.EvalFunc("println", ModuleBase); !errors.Is(err, ErrFinalized) {
+		t.Fatal("expected ErrFinalized, got", err)
+	}
+
+	var x int64
+	if err := r.Unmarshal(nil, &x); !errors.Is(err, ErrFinalized) {
+		t.Fatal("expected ErrFinalized, got", err)
+	}
+}
+
+func TestRuntimeConcurrentCalls(t *testing.T) {
+	var wg sync.WaitGroup
+	errC := make(chan error, 16)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			arg, err := Marshal(int64(i))
+			if err != nil {
+				errC <- err
+				return
+			}
+
+			resp, err := EvalFunc("abs2", ModuleBase, arg)
+			if err != nil {
+				errC <- err
+				return
+			}
+
+			var n int64
+			if err := Unmarshal(resp, &n); err != nil {
+				errC <- err
+				return
+			}
+
+			if n != int64(i*i) {
+				errC <- fmt.Errorf("expected %d, got %d", i*i, n)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errC)
+
+	for err := range errC {
+		t.Fatal(err)
+	}
+}
+
+func TestExecutorPanic(t *testing.T) {
+	e := newExecutor()
+
+	if err := e.do(func() error { panic("unexpected") }); err == nil {
+		t.Fatal("expected panic to be returned as error")
+	}
+
+	// executor keeps serving requests after a panic
+	if err := e.do(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEvalContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := EvalContext(ctx, "sleep(5)"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected context.DeadlineExceeded, got", err)
+	}
+
+	if time.Since(start) > 2*time.Second {
+		t.Fatal("expected call to return shortly after deadline")
+	}
+
+	// runtime remains usable after interrupting julia code, which does
+	// not keep running until sleep completes
+	if _, err := Eval("1 + 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// julia code is interrupted on linux only
+	if runtime.GOOS == "linux" && time.Since(start) > 2*time.Second {
+		t.Fatal("expected julia code to be interrupted, follow-up call took", time.Since(start))
+	}
+}
+
+func TestEvalFuncContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := EvalFuncContext(ctx, "time", ModuleBase); !errors.Is(err, context.Canceled) {
+		t.Fatal("expected context.Canceled, got", err)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	x, err := Marshal([]float64{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// identity returns the same julia object, which is rooted once more
+	y, err := EvalFunc("identity", ModuleBase, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.rooted || !y.rooted || x.slot == y.slot {
+		t.Fatal("expected values to be rooted in distinct slots, got", x.slot, y.slot)
+	}
+
+	if err := x.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Eval("GC.gc(true)"); err != nil {
+		t.Fatal(err)
+	}
+
+	// y is still rooted after x is released
+	var sum float64
+	resp, err := EvalFunc("sum", ModuleBase, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(resp, &sum); err != nil || sum != 6 {
+		t.Fatal("expected 6, got", sum, err)
+	}
+
+	if err := x.Release(); err != nil {
+		t.Fatal("expected repeated release to be a no-op, got", err)
+	}
+
+	if _, err := EvalFunc("sum", ModuleBase, x); !errors.Is(err, ErrReleased) {
+		t.Fatal("expected ErrReleased, got", err)
+	}
+
+	if err := Unmarshal(x, &sum); !errors.Is(err, ErrReleased) {
+		t.Fatal("expected ErrReleased, got", err)
+	}
+
+	if err := y.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRootedValuesSurviveGC(t *testing.T) {
+	values := make([]*Value, 100)
+	for i := range values {
+		x, err := NewMat(make([]int64, 1000), 10, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range x.elms {
+			x.elms[j] = int64(i)
+		}
+
+		if values[i], err = Marshal(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Eval("GC.gc(true)"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, value := range values {
+		x := new(Mat[int64])
+		if err := Unmarshal(value, x); err != nil {
+			t.Fatal(err)
+		}
+
+		if x.elms[len(x.elms)-1] != int64(i) {
+			t.Fatal("expected rooted array to be intact after garbage collection, got", x.elms[len(x.elms)-1])
+		}
+
+		if err := value.Release(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRootedScalarsSurviveGC(t *testing.T) {
+	// equal isbits values are distinct boxes, each of which is rooted
+	values := make([]*Value, 100)
+	for i := range values {
+		var err error
+		if values[i], err = Marshal(1.5); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sum, err := EvalFunc("+", ModuleBase, values[0], values[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = append(values, sum)
+
+	// freed boxes would be reused by boxes of other values
+	if _, err := Eval("GC.gc(true); global __testBoxes = Any[float(i) for i in 1:100_000]; nothing"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _, _ = Eval("global __testBoxes = nothing") }()
+
+	for i, value := range values {
+		expected := 1.5
+		if i == len(values)-1 {
+			expected = 3
+		}
+
+		var x float64
+		if err := Unmarshal(value, &x); err != nil {
+			t.Fatal(err)
+		}
+
+		if x != expected {
+			t.Fatal("expected rooted scalar to be intact after garbage collection, got", x)
+		}
+
+		if err := value.Release(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReleaseLater(t *testing.T) {
+	r := current()
+
+	x, err := Marshal(int64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// values garbage collected by go are released before next call
+	r.releaseLater(x)
+	if _, err := Eval("nothing"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := EvalFunc("identity", ModuleBase, x); !errors.Is(err, ErrReleased) {
+		t.Fatal("expected ErrReleased, got", err)
+	}
+}
@@ -0,0 +1,67 @@
+package julia
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"unicode/utf8"
+)
+
+// Symbol corresponds to julia Symbol, such as :U passed to cholesky
+type Symbol string
+
+// Char corresponds to julia Char. rune cannot be used, since it is
+// an alias of int32, which corresponds to julia Int32
+type Char rune
+
+// NothingType is the type of Nothing
+type NothingType struct{}
+
+// MissingType is the type of Missing
+type MissingType struct{}
+
+var (
+	// Nothing corresponds to julia nothing, which is returned by functions
+	// without a return value, such as println
+	Nothing NothingType
+
+	// Missing corresponds to julia missing, which represents missing data
+	Missing MissingType
+)
+
+// String returns julia representation of the symbol
+func (s Symbol) String() string {
+	return ":" + string(s)
+}
+
+// String returns the character as a string
+func (c Char) String() string {
+	return string(rune(c))
+}
+
+// encode returns julia representation of the character, which is its
+// UTF-8 encoding left aligned in 32 bits
+func (c Char) encode() uint32 {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], rune(c))
+
+	var u uint32
+	for i := 0; i < n; i++ {
+		u |= uint32(buf[i]) << (24 - 8*i)
+	}
+
+	return u
+}
+
+// decodeChar returns character of julia representation u. Malformed
+// characters are decoded as utf8.RuneError
+func decodeChar(u uint32) Char {
+	if u == 0 {
+		return 0
+	}
+
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], u)
+
+	r, _ := utf8.DecodeRune(buf[:4-bits.TrailingZeros32(u)/8])
+	return Char(r)
+}
@@ -0,0 +1,42 @@
+package julia
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestScalarsCharEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		c Char
+		u uint32
+	}{
+		{c: 0, u: 0x00000000},
+		{c: 'a', u: 0x61000000},
+		{c: 'α', u: 0xceb10000},
+		{c: '€', u: 0xe282ac00},
+		{c: '😀', u: 0xf09f9880},
+	} {
+		if u := tc.c.encode(); u != tc.u {
+			t.Fatalf("expected %#08x for %q, got %#08x", tc.u, tc.c, u)
+		}
+
+		if c := decodeChar(tc.u); c != tc.c {
+			t.Fatalf("expected %q for %#08x, got %q", tc.c, tc.u, c)
+		}
+	}
+
+	// julia allows malformed characters, such as '\xff'
+	if c := decodeChar(0xff000000); c != utf8.RuneError {
+		t.Fatal("expected RuneError, got", c)
+	}
+}
+
+func TestScalarsString(t *testing.T) {
+	if s := Symbol("U").String(); s != ":U" {
+		t.Fatal("expected :U, got", s)
+	}
+
+	if s := Char('α').String(); s != "α" {
+		t.Fatal("expected α, got", s)
+	}
+}
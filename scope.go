@@ -0,0 +1,147 @@
+package julia
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// Scope tracks values created through it and releases them at once when
+// the scope ends, which saves releasing intermediate values one by one:
+//
+//	err := julia.WithScope(func(s *julia.Scope) error {
+//		x, err := s.Marshal(mat)
+//		if err != nil {
+//			return err
+//		}
+//
+//		y, err := s.EvalFunc("inv", julia.ModuleBase, x)
+//		if err != nil {
+//			return err
+//		}
+//
+//		return julia.Unmarshal(y, mat)
+//	})
+//
+// Values are rooted until the scope is released and cannot be used after.
+// Scope is safe for concurrent use.
+type Scope struct {
+	rt *Runtime
+
+	mu     sync.Mutex
+	values []*Value
+}
+
+// NewScope creates a scope for the runtime package level functions
+// forward to. Values created through the scope are released via Release
+func NewScope() *Scope {
+	return current().NewScope()
+}
+
+// WithScope runs f with a scope for the runtime package level functions
+// forward to and releases values created through the scope once f returns
+func WithScope(f func(s *Scope) error) error {
+	return current().WithScope(f)
+}
+
+// NewScope creates a scope for the runtime. Values created through the
+// scope are released via Release
+func (r *Runtime) NewScope() *Scope {
+	return &Scope{rt: r}
+}
+
+// WithScope runs f with a scope for the runtime and releases values
+// created through the scope once f returns
+func (r *Runtime) WithScope(f func(s *Scope) error) (err error) {
+	s := r.NewScope()
+	defer func() {
+		if rerr := s.Release(); err == nil {
+			err = rerr
+		}
+	}()
+
+	return f(s)
+}
+
+// Eval is like Runtime.Eval, however, the value is released with the scope
+func (s *Scope) Eval(input string) (*Value, error) {
+	return s.track(s.rt.Eval(input))
+}
+
+// EvalFunc is like Runtime.EvalFunc, however, the value is released with the scope
+func (s *Scope) EvalFunc(name string, moduleType ModuleType, args ...*Value) (*Value, error) {
+	return s.track(s.rt.EvalFunc(name, moduleType, args...))
+}
+
+// EvalContext is like Runtime.EvalContext, however, the value is released
+// with the scope
+func (s *Scope) EvalContext(ctx context.Context, input string) (*Value, error) {
+	return s.track(s.rt.EvalContext(ctx, input))
+}
+
+// EvalFuncContext is like Runtime.EvalFuncContext, however, the value is
+// released with the scope
+func (s *Scope) EvalFuncContext(ctx context.Context, name string, moduleType ModuleType, args ...*Value) (*Value, error) {
+	return s.track(s.rt.EvalFuncContext(ctx, name, moduleType, args...))
+}
+
+// Marshal is like Runtime.Marshal, however, the value is released with the scope
+func (s *Scope) Marshal(x any) (*Value, error) {
+	return s.track(s.rt.Marshal(x))
+}
+
+// Len returns number of values tracked by the scope
+func (s *Scope) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.values)
+}
+
+// Release releases all values created through the scope in one call to
+// julia runtime. Scope can be used again once released
+func (s *Scope) Release() error {
+	s.mu.Lock()
+	values := s.values
+	s.values = nil
+	s.mu.Unlock()
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	for _, value := range values {
+		runtime.SetFinalizer(value, nil)
+	}
+
+	err := s.rt.do(func() error {
+		var err error
+		for _, value := range values {
+			if e := release(value); e != nil && err == nil {
+				err = e
+			}
+		}
+
+		return err
+	})
+	if errors.Is(err, ErrFinalized) {
+		// values no longer exist once julia runtime is finalized
+		return nil
+	}
+
+	return err
+}
+
+// track adds value to the scope
+func (s *Scope) track(value *Value, err error) (*Value, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = append(s.values, value)
+	return value, nil
+}
@@ -0,0 +1,97 @@
+package julia
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithScope(t *testing.T) {
+	var values []*Value
+	err := WithScope(func(s *Scope) error {
+		x, err := s.Marshal([]float64{1, 2, 3})
+		if err != nil {
+			return err
+		}
+
+		y, err := s.EvalFunc("sum", ModuleBase, x)
+		if err != nil {
+			return err
+		}
+
+		var sum float64
+		if err := Unmarshal(y, &sum); err != nil {
+			return err
+		}
+
+		if sum != 6 {
+			t.Fatal("expected sum of 6, got", sum)
+		}
+
+		if n := s.Len(); n != 2 {
+			t.Fatal("expected scope to track 2 values, got", n)
+		}
+
+		values = append(values, x, y)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range values {
+		if _, err := EvalFunc("identity", ModuleBase, value); !errors.Is(err, ErrReleased) {
+			t.Fatal("expected values to be released with the scope, got", err)
+		}
+	}
+}
+
+func TestWithScopeError(t *testing.T) {
+	var x *Value
+	err := WithScope(func(s *Scope) error {
+		var err error
+		if x, err = s.Eval("[1, 2, 3]"); err != nil {
+			return err
+		}
+
+		_, err = s.Eval("error(\"boom\")")
+		return err
+	})
+
+	var jErr *JuliaError
+	if !errors.As(err, &jErr) {
+		t.Fatal("expected julia error to be returned from the scope, got", err)
+	}
+
+	if _, err := EvalFunc("identity", ModuleBase, x); !errors.Is(err, ErrReleased) {
+		t.Fatal("expected values to be released when scope fails, got", err)
+	}
+}
+
+func TestScopeReuse(t *testing.T) {
+	s := NewScope()
+
+	if _, err := s.Marshal(int64(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := s.Len(); n != 0 {
+		t.Fatal("expected released scope to be empty, got", n)
+	}
+
+	x, err := s.Marshal(int64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := x.Release(); err != nil {
+		t.Fatal("expected releasing a released value to be a no-op, got", err)
+	}
+}
@@ -0,0 +1,219 @@
+package julia
+
+/*
+#include "jlapi.h"
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+)
+
+// structTag is the struct tag setting julia name of a field, such as
+// `julia:"name"`. Fields tagged with "-" are skipped
+const structTag = "julia"
+
+// structField is an exported field of go struct along with its julia name
+type structField struct {
+	name  string
+	index int
+}
+
+// structFields returns exported fields of struct type t. julia names
+// default to go field names
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Tag.Get(structTag)
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+
+		fields = append(fields, structField{name: name, index: i})
+	}
+
+	return fields
+}
+
+// structOf returns addressable struct x holds, i.e. for x of a struct
+// type or a non-nil pointer to it
+func structOf(x any) (reflect.Value, bool) {
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+
+		return v.Elem(), true
+	}
+
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	// fields are addressed when marshaling matrices held by value
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Elem(), true
+}
+
+// isStructPointer checks if x is a non-nil pointer to a struct, which
+// can be unmarshaled into
+func isStructPointer(x any) bool {
+	v := reflect.ValueOf(x)
+	return v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct
+}
+
+// isStruct checks if julia value has fields, such as a NamedTuple. arrays
+// are excluded, since they have fields in julia 1.11 or newer
+func isStruct(value *C.jl_value_t) bool {
+	if C.gojl_is_array(value) != 0 {
+		return false
+	}
+
+	return C.gojl_svec_len(C.gojl_field_types(C.gojl_typeof(value))) > 0
+}
+
+// marshalStruct creates julia NamedTuple of exported fields of v, which
+// are marshaled as if passed to Marshal
+func marshalStruct(v reflect.Value) (*Value, error) {
+	fields := structFields(v.Type())
+	names := make([]*C.jl_value_t, len(fields))
+	elms := make([]*C.jl_value_t, len(fields))
+	for i, field := range fields {
+		value, err := marshal(fieldOf(v.Field(field.index)))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.name, err)
+		}
+
+		// fields are not referenced by julia until the named tuple is
+		// created, whereas symbols are never collected
+		tmp := &Value{value: value.value}
+		if err := root(tmp); err != nil {
+			return nil, err
+		}
+		defer func() { _ = release(tmp) }()
+
+		names[i] = newSymbol(Symbol(field.name))
+		elms[i] = value.value
+	}
+
+	f, err := getFunction(jlNamedTuple, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	// names are rooted while tuple of elements is created
+	tmp := &Value{value: newTuple(names)}
+	if err := root(tmp); err != nil {
+		return nil, err
+	}
+	defer func() { _ = release(tmp) }()
+
+	return call(f, tmp.value, newTuple(elms))
+}
+
+// fieldOf returns value of struct field to marshal. nil pointers are
+// marshaled as nothing and other pointers as values they point to,
+// whereas matrices, big numbers and nested structs are marshaled via
+// their pointers
+func fieldOf(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.Elem().Kind() != reflect.Struct || isScalarStruct(v.Elem()) {
+			return v.Elem().Interface()
+		}
+	case reflect.Struct:
+		if isScalarStruct(v) {
+			return v.Interface()
+		}
+
+		return v.Addr().Interface()
+	}
+
+	return v.Interface()
+}
+
+// isScalarStruct checks if struct v represents a julia scalar, which is
+// marshaled by value rather than as a NamedTuple
+func isScalarStruct(v reflect.Value) bool {
+	switch v.Interface().(type) {
+	case Int128, UInt128, NothingType, MissingType:
+		return true
+	}
+
+	return false
+}
+
+// unmarshalStruct populates exported fields of struct v from fields of
+// julia value by their names. Fields missing in julia value are left
+// untouched
+func unmarshalStruct(data *Value, v reflect.Value) error {
+	f, err := getFunction(jlFieldNames, ModuleMain)
+	if err != nil {
+		return err
+	}
+
+	names, err := call(f, C.gojl_typeof(data.value))
+	if err != nil {
+		return err
+	}
+
+	indices := make(map[string]int)
+	for i := 0; i < int(C.gojl_svec_len(names.value)); i++ {
+		indices[C.GoString(C.gojl_symbol_name(C.gojl_svec_ref(names.value, C.size_t(i))))] = i
+	}
+
+	for _, field := range structFields(v.Type()) {
+		i, ok := indices[field.name]
+		if !ok {
+			continue
+		}
+
+		// isbits fields are boxed by jl_get_nth_field and are not referenced
+		// by julia while being unmarshaled
+		elm := &Value{value: C.jl_get_nth_field(data.value, C.size_t(i)), rt: data.rt}
+		if err := rooted(elm.value, func() error { return unmarshalField(elm, v.Field(field.index)) }); err != nil {
+			return fmt.Errorf("field %s: %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalField unpacks julia value into struct field v. Pointers are
+// set to nil for nothing and are allocated as needed otherwise
+func unmarshalField(data *Value, v reflect.Value) error {
+	if v.Kind() != reflect.Ptr {
+		return unmarshal(data, v.Addr().Interface())
+	}
+
+	if data.value == C.gojl_nothing() {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	p := v
+	if p.IsNil() {
+		p = reflect.New(v.Type().Elem())
+	}
+
+	if err := unmarshal(data, p.Interface()); err != nil {
+		return err
+	}
+
+	v.Set(p)
+	return nil
+}
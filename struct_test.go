@@ -0,0 +1,160 @@
+package julia
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testPoint struct {
+	X float64 `julia:"x"`
+	Y float64 `julia:"y"`
+}
+
+type testParams struct {
+	Alpha   float64   `julia:"alpha"`
+	Label   string    `julia:"label"`
+	Weights []float64 `julia:"weights"`
+	Origin  testPoint `julia:"origin"`
+	Scale   *float64  `julia:"scale"`
+	Ignored int       `julia:"-"`
+	hidden  int
+}
+
+func TestStructFields(t *testing.T) {
+	fields := structFields(reflect.TypeOf(testParams{}))
+
+	var names []string
+	for _, field := range fields {
+		names = append(names, field.name)
+	}
+
+	expected := []string{"alpha", "label", "weights", "origin", "scale"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatal("expected", expected, "got", names)
+	}
+
+	type untagged struct {
+		Alpha float64
+	}
+
+	if fields := structFields(reflect.TypeOf(untagged{})); len(fields) != 1 || fields[0].name != "Alpha" {
+		t.Fatal("expected go field name Alpha, got", fields)
+	}
+}
+
+func TestMarshalStruct(t *testing.T) {
+	arg, err := MarshalStruct(testParams{
+		Alpha:   2,
+		Label:   "abcd",
+		Weights: []float64{1, 2, 3},
+		Origin:  testPoint{X: 1, Y: -1},
+		Ignored: 1,
+		hidden:  1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Eval(`weighted(p) = p.alpha * sum(p.weights) + p.origin.x + (p.scale === nothing ? 0 : 1)`); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := EvalFunc("weighted", ModuleMain, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out float64
+	if err := Unmarshal(resp, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out != 13 {
+		t.Fatal("expected 13, got", out)
+	}
+
+	if _, err := MarshalStruct(1); err == nil {
+		t.Fatal("should have failed for int")
+	}
+
+	if _, err := MarshalStruct(struct{ N int }{}); err == nil {
+		t.Fatal("should have failed for field of type int")
+	}
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	resp, err := Eval(`(alpha = 2.5, label = "abcd", origin = (x = 1.0, y = 2.0, z = 3.0), scale = 0.5, extra = 1)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// weights are missing in julia value and are left untouched
+	out := testParams{Weights: []float64{1}}
+	if err := UnmarshalStruct(resp, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Alpha != 2.5 || out.Label != "abcd" || out.Origin != (testPoint{X: 1, Y: 2}) ||
+		out.Scale == nil || *out.Scale != 0.5 || len(out.Weights) != 1 {
+		t.Fatal("did not receive expected values", out)
+	}
+
+	// fields of any julia struct are matched by names
+	if _, err := Eval(`struct TestPoint; x::Float64; y::Float64; end`); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = Eval(`TestPoint(3.0, 4.0)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p testPoint
+	if err := UnmarshalStruct(resp, &p); err != nil || p != (testPoint{X: 3, Y: 4}) {
+		t.Fatal("did not receive expected values", p, err)
+	}
+
+	resp, err = Eval(`(x = "abcd", y = 1.0)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UnmarshalStruct(resp, &p); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	resp, err = Eval(`[1.0, 2.0]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UnmarshalStruct(resp, &p); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+}
+
+func TestMarshalStruct128BitFields(t *testing.T) {
+	type wide struct {
+		N Int128   `julia:"n"`
+		U *UInt128 `julia:"u"`
+	}
+
+	arg, err := MarshalStruct(wide{N: Int128{Lo: 1, Hi: 1}, U: &UInt128{Lo: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "@NamedTuple{n::Int128, u::UInt128}" {
+		t.Fatal("expected @NamedTuple{n::Int128, u::UInt128}, got", typeName)
+	}
+
+	var out wide
+	if err := UnmarshalStruct(arg, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.N != (Int128{Lo: 1, Hi: 1}) || out.U == nil || *out.U != (UInt128{Lo: 2}) {
+		t.Fatal("did not receive expected values", out)
+	}
+}
@@ -0,0 +1,84 @@
+package julia
+
+/*
+#include "jlapi.h"
+*/
+import "C"
+import "fmt"
+
+// Tuple is marshaled to julia Tuple of its elements, each of which is
+// marshaled as if passed to Marshal. []any is marshaled the same way
+type Tuple []any
+
+// marshalTuple creates julia tuple of elements of v in one go, i.e.
+// without calling julia functions, using tuple type of element types
+func marshalTuple(v []any) (*Value, error) {
+	elms := make([]*C.jl_value_t, len(v))
+	for i := range v {
+		value, err := marshal(v[i])
+		if err != nil {
+			return nil, fmt.Errorf("tuple element %d: %w", i+1, err)
+		}
+
+		// elements are not referenced by julia until the tuple is created
+		tmp := &Value{value: value.value}
+		if err := root(tmp); err != nil {
+			return nil, err
+		}
+		defer func() { _ = release(tmp) }()
+
+		elms[i] = value.value
+	}
+
+	return &Value{value: newTuple(elms)}, nil
+}
+
+// newTuple creates julia tuple of elms, which must be rooted meanwhile
+func newTuple(elms []*C.jl_value_t) *C.jl_value_t {
+	types := make([]*C.jl_value_t, len(elms))
+	for i := range elms {
+		types[i] = C.gojl_typeof(elms[i])
+	}
+
+	// empty tuple has no elements to point to
+	var pElms, pTypes **C.jl_value_t
+	if len(elms) > 0 {
+		pElms, pTypes = &elms[0], &types[0]
+	}
+
+	tupleType := C.gojl_tuple_type(pTypes, C.size_t(len(elms)))
+	return C.jl_new_structv(tupleType, pElms, C.uint32_t(len(elms)))
+}
+
+// unmarshalTuple unpacks elements of julia tuple into x, which must match
+// the number of elements
+func unmarshalTuple(data *Value, x ...any) error {
+	if data == nil || data.value == nil {
+		return fmt.Errorf("%w: cannot unmarshal null julia value into tuple", ErrTypeMismatch)
+	}
+
+	if data.released {
+		return ErrReleased
+	}
+
+	value := data.value
+	if C.gojl_is_tuple(value) == 0 {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into tuple", ErrTypeMismatch, typeOf(data))
+	}
+
+	// parameters of a concrete tuple type are types of its elements
+	n := int(C.gojl_svec_len(getField(C.gojl_typeof(value), "parameters")))
+	if n != len(x) {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %d values",
+			ErrShapeMismatch, typeOf(data), len(x))
+	}
+
+	for i := range x {
+		elm := &Value{value: C.jl_get_nth_field(value, C.size_t(i)), rt: data.rt}
+		if err := rooted(elm.value, func() error { return unmarshal(elm, x[i]) }); err != nil {
+			return fmt.Errorf("tuple element %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package julia
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnmarshalTupleFindmax(t *testing.T) {
+	arg, err := Marshal([]float64{1, 5, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := EvalFunc("findmax", ModuleBase, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var max float64
+	var index int64
+	if err := UnmarshalTuple(resp, &max, &index); err != nil {
+		t.Fatal(err)
+	}
+
+	if max != 5 || index != 2 {
+		t.Fatal("expected (5.0, 2), got", max, index)
+	}
+
+	if err := UnmarshalTuple(resp, &max); !errors.Is(err, ErrShapeMismatch) {
+		t.Fatal("expected ErrShapeMismatch, got", err)
+	}
+
+	if err := UnmarshalTuple(resp, &index, &max); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch, got", err)
+	}
+
+	if err := UnmarshalTuple(arg, &max); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch for a vector, got", err)
+	}
+}
+
+func TestMarshalTuple(t *testing.T) {
+	mat, err := NewMat([]float64{1, 2, 3, 4}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := Marshal(Tuple{int64(1), "abcd", mat, Tuple{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if typeName := arg.Type(); typeName != "Tuple{Int64, String, Matrix{Float64}, Tuple{}}" {
+		t.Fatal("expected Tuple{Int64, String, Matrix{Float64}, Tuple{}}, got", typeName)
+	}
+
+	var n int64
+	var s string
+	var empty NothingType
+	out := new(Mat[float64])
+	if err := UnmarshalTuple(arg, &n, &s, out, &empty); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatal("expected ErrTypeMismatch for empty tuple, got", err)
+	}
+
+	// the same values are read back, whereas []any is marshaled as Tuple
+	arg, err = Marshal([]any{int64(1), "abcd", mat})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UnmarshalTuple(arg, &n, &s, out); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 || s != "abcd" || !equalDims(out.GetDims(), []int{2, 2}) || out.GetElms()[3] != 4 {
+		t.Fatal("did not receive expected values", n, s, out.GetDims(), out.GetElms())
+	}
+}
@@ -1,13 +1,27 @@
 package julia
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+)
 
-// PrimitiveTypes are type constraint on julia input
+// PrimitiveTypes are type constraint on julia input and on elements of
+// Mat. Float16 and Char satisfy the constraint via their underlying types
 type PrimitiveTypes interface {
 	~bool |
 		~uint8 | ~uint16 | ~uint32 | ~uint64 |
 		~int8 | ~int16 | ~int32 | ~int64 |
-		~float32 | ~float64
+		~float32 | ~float64 |
+		~complex64 | ~complex128 |
+		Int128 | UInt128
+}
+
+// ScalarTypes are type constraints on julia input that are not stored
+// inline in julia arrays, hence they are not supported as elements of Mat.
+// Symbol satisfies the constraint via its underlying type
+type ScalarTypes interface {
+	~string |
+		NothingType | MissingType
 }
 
 // PrimitiveSliceTypes are type constraints on julia inputs
@@ -15,28 +29,75 @@ type PrimitiveSliceTypes interface {
 	~[]bool |
 		~[]uint8 | ~[]uint16 | ~[]uint32 | ~[]uint64 |
 		~[]int8 | ~[]int16 | ~[]int32 | ~[]int64 |
-		~[]float32 | ~[]float64
+		~[]float32 | ~[]float64 |
+		~[]complex64 | ~[]complex128 |
+		~[]Int128 | ~[]UInt128 | ~[]Float16 |
+		~[]string
 }
 
-// PrimitivePointerTypes are type constraints on julia output
+// PrimitivePointerTypes are type constraints on julia output.
+// Vector{String} is unmarshaled into a pointer to string slice
 type PrimitivePointerTypes interface {
 	~*bool |
 		~*uint8 | ~*uint16 | ~*uint32 | ~*uint64 |
 		~*int8 | ~*int16 | ~*int32 | ~*int64 |
-		~*float32 | ~*float64
+		~*float32 | ~*float64 |
+		~*complex64 | ~*complex128 |
+		~*Int128 | ~*UInt128 | ~*Float16 |
+		~*string | ~*[]string |
+		~*Symbol | ~*Char |
+		*NothingType | *MissingType
 }
 
 // MatTypes represents constraints on parametrized Mat type
-// to be uses as both julia inputs and outputs
+// to be uses as both julia inputs and outputs. Strings are not stored
+// inline in julia arrays, hence Mat[string] is not supported
 type MatTypes interface {
 	*Mat[bool] |
 		*Mat[uint8] | *Mat[uint16] | *Mat[uint32] | *Mat[uint64] |
 		*Mat[int8] | *Mat[int16] | *Mat[int32] | *Mat[int64] |
-		*Mat[float32] | *Mat[float64]
+		*Mat[float32] | *Mat[float64] |
+		*Mat[complex64] | *Mat[complex128] |
+		*Mat[Int128] | *Mat[UInt128] | *Mat[Float16]
+}
+
+// MaskedMatTypes represents constraints on parametrized MaskedMat type
+// to be used as both julia inputs and outputs
+type MaskedMatTypes interface {
+	*MaskedMat[bool] |
+		*MaskedMat[uint8] | *MaskedMat[uint16] | *MaskedMat[uint32] | *MaskedMat[uint64] |
+		*MaskedMat[int8] | *MaskedMat[int16] | *MaskedMat[int32] | *MaskedMat[int64] |
+		*MaskedMat[float32] | *MaskedMat[float64] |
+		*MaskedMat[complex64] | *MaskedMat[complex128] |
+		*MaskedMat[Int128] | *MaskedMat[UInt128] | *MaskedMat[Float16]
+}
+
+// PointerSliceTypes are type constraints on julia output of vectors with
+// missing values, whose missing elements are unmarshaled as nil
+type PointerSliceTypes interface {
+	*[]*bool |
+		*[]*uint8 | *[]*uint16 | *[]*uint32 | *[]*uint64 |
+		*[]*int8 | *[]*int16 | *[]*int32 | *[]*int64 |
+		*[]*float32 | *[]*float64 |
+		*[]*complex64 | *[]*complex128 |
+		*[]*Int128 | *[]*UInt128 | *[]*Float16
+}
+
+// TupleTypes are type constraints on julia tuple input, i.e. Tuple or []any
+type TupleTypes interface {
+	~[]any
+}
+
+// BigTypes are arbitrary precision numbers, which are marshaled to julia
+// BigInt and BigFloat and are unmarshaled into as pointers
+type BigTypes interface {
+	*big.Int | *big.Float
 }
 
 // Mat represents the matrix for supported data types
-// parameterized by primitive types
+// parameterized by primitive types. An empty Mat, such as new(Mat[float64]),
+// can be used to unmarshal julia arrays of any shape, in which case
+// its dims and elements are populated from the julia array
 type Mat[T PrimitiveTypes] struct {
 	elms []T
 	dims []int
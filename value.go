@@ -0,0 +1,378 @@
+package julia
+
+/*
+#include <stdlib.h>
+#include "jlapi.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// Value represents generic data type to pass to/from Julia runtime.
+// Users are not expected to instantiate this struct and an instance
+// of it is typically accessed via Marshal/Unmarshal functions.
+//
+// Values returned by a runtime are rooted, i.e. julia garbage collector
+// does not free them until they are released.
+type Value struct {
+	value    *C.jl_value_t
+	rt       *Runtime
+	released bool
+
+	// slot of __jlRoots holding the value while it is rooted
+	slot   int
+	rooted bool
+}
+
+// runtime returns runtime the value belongs to
+func (g *Value) runtime() *Runtime {
+	if g == nil || g.rt == nil {
+		return current()
+	}
+
+	return g.rt
+}
+
+// Type evaluates to julia representation of typeof
+func (g *Value) Type() string {
+	var typeName string
+	_ = g.runtime().do(func() error {
+		typeName = typeOf(g)
+		return nil
+	})
+
+	return typeName
+}
+
+// TypeOf returns julia type of the value or nil if the value is released
+func (g *Value) TypeOf() *DataType {
+	var dataType *DataType
+	_ = g.runtime().do(func() error {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		dataType = newDataType(C.gojl_typeof(g.value))
+		return nil
+	})
+
+	return dataType
+}
+
+// IsNothing checks if value is julia nothing
+func (g *Value) IsNothing() bool {
+	return g.is(func(value *C.jl_value_t) bool {
+		return value == C.gojl_nothing()
+	})
+}
+
+// IsMissing checks if value is julia missing
+func (g *Value) IsMissing() bool {
+	return g.is(isMissing)
+}
+
+// IsSymbol checks if value is a julia Symbol
+func (g *Value) IsSymbol() bool {
+	return g.is(func(value *C.jl_value_t) bool {
+		return isType(value, Symbol(""))
+	})
+}
+
+// IsChar checks if value is a julia Char
+func (g *Value) IsChar() bool {
+	return g.is(func(value *C.jl_value_t) bool {
+		return isType(value, Char(0))
+	})
+}
+
+// IsString checks if value is a julia String
+func (g *Value) IsString() bool {
+	return g.is(func(value *C.jl_value_t) bool {
+		return isType(value, "")
+	})
+}
+
+// IsArray checks if value is a julia Array
+func (g *Value) IsArray() bool {
+	return g.is(func(value *C.jl_value_t) bool {
+		return C.gojl_is_array(value) != 0
+	})
+}
+
+// is evaluates predicate f on the value, which is false for released values
+func (g *Value) is(f func(value *C.jl_value_t) bool) bool {
+	var ok bool
+	_ = g.runtime().do(func() error {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		ok = f(g.value)
+		return nil
+	})
+
+	return ok
+}
+
+// NDims returns number of dimensions of the value as reported by julia
+// ndims, which is 0 for scalars and values ndims is not defined for
+func (g *Value) NDims() int {
+	var n int
+	_ = g.runtime().do(func() error {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		n = ndims(g)
+		return nil
+	})
+
+	return n
+}
+
+// Size returns dimensions of the value as reported by julia size
+func (g *Value) Size() ([]int, error) {
+	var dims []int
+	err := g.runtime().do(func() (err error) {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		dims, err = size(g)
+		return err
+	})
+
+	return dims, err
+}
+
+// Eltype returns element type of the value as reported by julia eltype
+func (g *Value) Eltype() (*DataType, error) {
+	var dataType *DataType
+	err := g.runtime().do(func() error {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		resp, err := evalFunc("eltype", ModuleBase, g)
+		if err != nil {
+			return err
+		}
+
+		dataType = newDataType(resp.value)
+		return nil
+	})
+
+	return dataType, err
+}
+
+// String returns julia representation of the value via repr
+func (g *Value) String() string {
+	var s string
+	_ = g.runtime().do(func() error {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		s = repr(g.value)
+		return nil
+	})
+
+	return s
+}
+
+// Field returns field of the value by its name, i.e. x.name in julia.
+// An exception thrown by julia runtime, such as for a missing field, is
+// returned as *JuliaError
+func (g *Value) Field(name string) (*Value, error) {
+	r := g.runtime()
+
+	var value *Value
+	err := r.do(func() (err error) {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		value, err = r.keep(field(g, name))
+		return err
+	})
+
+	return value, err
+}
+
+// Index returns element of the value at julia indices, i.e. x[i...] in
+// julia. Indices are 1-based as in julia. An exception thrown by julia
+// runtime, such as for an index out of bounds, is returned as *JuliaError
+func (g *Value) Index(i ...int) (*Value, error) {
+	r := g.runtime()
+
+	var value *Value
+	err := r.do(func() (err error) {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		value, err = r.keep(index(g, i...))
+		return err
+	})
+
+	return value, err
+}
+
+// Length returns julia length of the value
+func (g *Value) Length() int {
+	var n int
+	_ = g.runtime().do(func() error {
+		if err := check(g); err != nil {
+			return err
+		}
+
+		n = length(g)
+		return nil
+	})
+
+	return n
+}
+
+// Release releases the value, allowing julia garbage collector to free it.
+// Value cannot be used once released. Releasing a value more than once
+// is a no-op
+func (g *Value) Release() error {
+	if g == nil {
+		return nil
+	}
+
+	runtime.SetFinalizer(g, nil)
+
+	err := g.runtime().do(func() error {
+		return release(g)
+	})
+	if errors.Is(err, ErrFinalized) {
+		// values no longer exist once julia runtime is finalized
+		return nil
+	}
+
+	return err
+}
+
+// Len returns julia length of the value
+func Len(g *Value) int {
+	return g.Length()
+}
+
+// check returns an error if value cannot be accessed
+func check(g *Value) error {
+	if g == nil || g.value == nil {
+		return fmt.Errorf("invalid null julia value")
+	}
+
+	if g.released {
+		return ErrReleased
+	}
+
+	return nil
+}
+
+// ndims returns julia ndims of the value or 0 if it is not defined
+func ndims(g *Value) int {
+	if C.gojl_is_array(g.value) != 0 {
+		return int(C.gojl_array_rank(g.value))
+	}
+
+	resp, err := evalFunc("ndims", ModuleBase, g)
+	if err != nil {
+		return 0
+	}
+
+	var n int64
+	if err := unmarshal(resp, &n); err != nil {
+		return 0
+	}
+
+	return int(n)
+}
+
+// size returns julia size of the value, which is read directly from arrays
+func size(g *Value) ([]int, error) {
+	if C.gojl_is_array(g.value) != 0 {
+		dims := make([]int, int(C.gojl_array_rank(g.value)))
+		for i := range dims {
+			dims[i] = int(C.gojl_array_dim(g.value, C.int(i)))
+		}
+
+		return dims, nil
+	}
+
+	resp, err := evalFunc(jlSize, ModuleMain, g)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Mat[int64]{}
+	if err := unmarshal(resp, out); err != nil {
+		return nil, err
+	}
+
+	dims := make([]int, len(out.elms))
+	for i := range dims {
+		dims[i] = int(out.elms[i])
+	}
+
+	return dims, nil
+}
+
+// field calls getproperty on the value
+func field(g *Value, name string) (*Value, error) {
+	f, err := getFunction(jlField, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	return call(f, g.value, C.jl_cstr_to_string(cName))
+}
+
+// index calls getindex on the value. indices are passed as a vector,
+// which is splatted on julia side
+func index(g *Value, i ...int) (*Value, error) {
+	idx := make([]int64, len(i))
+	for k := range i {
+		idx[k] = int64(i[k])
+	}
+
+	// an empty vector is allowed, since x[] indexes zero dimensional
+	// containers in julia
+	m := &Mat[int64]{elms: idx, dims: []int{len(idx)}}
+
+	indices, err := marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := getFunction(jlIndex, ModuleMain)
+	if err != nil {
+		return nil, err
+	}
+
+	return call(f, g.value, indices.value)
+}
+
+// repr returns julia representation of value
+func repr(value *C.jl_value_t) string {
+	f, err := getFunction("repr", ModuleBase)
+	if err != nil {
+		return ""
+	}
+
+	resp, err := call(f, value)
+	if err != nil {
+		return ""
+	}
+
+	return C.GoString(C.jl_string_ptr(resp.value))
+}
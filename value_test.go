@@ -0,0 +1,155 @@
+package julia
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValueIntrospection(t *testing.T) {
+	x, err := Eval("reshape(collect(Int32, 1:6), 2, 3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Release()
+
+	if !x.IsArray() {
+		t.Fatal("expected value to be an array")
+	}
+
+	if x.IsNothing() {
+		t.Fatal("expected value not to be nothing")
+	}
+
+	if n := x.NDims(); n != 2 {
+		t.Fatal("expected 2 dims, got", n)
+	}
+
+	dims, err := x.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalDims(dims, []int{2, 3}) {
+		t.Fatal("expected size (2, 3), got", dims)
+	}
+
+	if n := x.Length(); n != 6 {
+		t.Fatal("expected length 6, got", n)
+	}
+
+	if typeName := x.TypeOf().String(); typeName != "Matrix{Int32}" {
+		t.Fatal("expected Matrix{Int32}, got", typeName)
+	}
+
+	el, err := x.Eltype()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if el.String() != "Int32" {
+		t.Fatal("expected Int32 element type, got", el)
+	}
+
+	if s := x.String(); s != "Int32[1 3 5; 2 4 6]" {
+		t.Fatal("expected repr of matrix, got", s)
+	}
+
+	y, err := x.Index(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer y.Release()
+
+	var n int32
+	if err := Unmarshal(y, &n); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 6 {
+		t.Fatal("expected x[2, 3] to be 6, got", n)
+	}
+
+	var jErr *JuliaError
+	if _, err := x.Index(3, 1); !errors.As(err, &jErr) || jErr.Type != "BoundsError" {
+		t.Fatal("expected BoundsError, got", err)
+	}
+}
+
+func TestValueScalar(t *testing.T) {
+	x, err := Marshal(int64(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Release()
+
+	if x.IsArray() {
+		t.Fatal("expected scalar not to be an array")
+	}
+
+	if n := x.NDims(); n != 0 {
+		t.Fatal("expected scalar to have 0 dims, got", n)
+	}
+
+	dims, err := x.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dims) != 0 {
+		t.Fatal("expected scalar to have empty size, got", dims)
+	}
+
+	if s := x.String(); s != "5" {
+		t.Fatal("expected 5, got", s)
+	}
+
+	nothing, err := Eval("nothing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nothing.Release()
+
+	if !nothing.IsNothing() {
+		t.Fatal("expected nothing")
+	}
+}
+
+func TestValueField(t *testing.T) {
+	x, err := Eval("1//3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Release()
+
+	den, err := x.Field("den")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer den.Release()
+
+	var n int64
+	if err := Unmarshal(den, &n); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 3 {
+		t.Fatal("expected denominator 3, got", n)
+	}
+
+	var jErr *JuliaError
+	if _, err := x.Field("missing"); !errors.As(err, &jErr) {
+		t.Fatal("expected julia error for missing field, got", err)
+	}
+
+	if err := x.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := x.Field("den"); !errors.Is(err, ErrReleased) {
+		t.Fatal("expected ErrReleased, got", err)
+	}
+
+	if x.TypeOf() != nil {
+		t.Fatal("expected no type for released value")
+	}
+}
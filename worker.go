@@ -0,0 +1,530 @@
+package julia
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// workerScript is the julia side of worker protocol
+//
+//go:embed worker.jl
+var workerScript string
+
+// workerCloseTimeout is how long Close waits for worker process to exit
+// before killing it
+const workerCloseTimeout = 5 * time.Second
+
+// Worker runs julia in a child process and exchanges values with it over
+// stdin and stdout. It offers the same API as the embedded runtime, however,
+// a crash of julia code, such as a segfault or a call to exit(), terminates
+// only the child process and is returned as ErrWorkerCrashed. The process
+// is restarted automatically on next call, invalidating all values created
+// by the previous process.
+//
+// Worker is safe for concurrent use, however, calls are executed one at a time.
+type Worker struct {
+	opts    *Options
+	preload []string
+
+	mu       sync.Mutex
+	proc     *workerProcess
+	gen      uint64
+	requests int
+	closed   bool
+
+	// procMu guards proc in addition to mu, so that kill does not wait
+	// for calls in flight, which hold mu while exchanging with the process
+	procMu sync.Mutex
+}
+
+// workerProcess is a running julia worker process
+type workerProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	done   chan struct{}
+	err    error
+}
+
+// WorkerValue is a reference to a value held by julia worker process
+type WorkerValue struct {
+	handle uint64
+	gen    uint64
+	w      *Worker
+}
+
+// NewWorker creates a worker with options, which may be nil to use julia
+// found in PATH. Options.BinDir is used to locate julia executable.
+// Worker process is started on first call or explicitly via Start
+func NewWorker(opts *Options) *Worker {
+	return &Worker{opts: opts}
+}
+
+// Start starts worker process if it is not running
+func (w *Worker) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.start()
+}
+
+// Close stops worker process. Worker cannot be used once closed
+func (w *Worker) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if w.proc == nil {
+		return nil
+	}
+
+	return w.stop()
+}
+
+// kill kills worker process, if any, without waiting for calls in flight,
+// which fail once the process is killed
+func (w *Worker) kill() {
+	w.procMu.Lock()
+	defer w.procMu.Unlock()
+
+	if w.proc != nil {
+		_ = w.proc.cmd.Process.Kill()
+	}
+}
+
+// setProc sets worker process, which is read by kill without holding mu
+func (w *Worker) setProc(proc *workerProcess) {
+	w.procMu.Lock()
+	defer w.procMu.Unlock()
+
+	w.proc = proc
+}
+
+// Eval evaluates input as if it were julia code. An exception thrown
+// by julia runtime is returned as *JuliaError
+func (w *Worker) Eval(input string) (*WorkerValue, error) {
+	req := &encoder{}
+	req.WriteByte(opEval)
+	req.writeString(input)
+
+	return w.value(req)
+}
+
+// EvalFunc evaluates a function literal, represented by name and module it is defined in,
+// and passes any optional arguments to it, returning any output from julia runtime.
+// An exception thrown by julia runtime is returned as *JuliaError
+func (w *Worker) EvalFunc(name string, moduleType ModuleType, args ...*WorkerValue) (*WorkerValue, error) {
+	req := &encoder{}
+	req.WriteByte(opCall)
+	switch moduleType {
+	case ModuleBase:
+		req.WriteByte(0)
+	case ModuleMain:
+		req.WriteByte(1)
+	default:
+		return nil, fmt.Errorf("invalid module type %d", moduleType)
+	}
+	req.writeString(name)
+	req.writeUint32(uint32(len(args)))
+	for _, arg := range args {
+		if err := w.check(arg); err != nil {
+			return nil, err
+		}
+		req.writeUint64(arg.handle)
+	}
+
+	return w.value(req, args...)
+}
+
+// Marshal sends x to worker process. Supported types are a subset of
+// those of package level Marshal, i.e. primitive types, strings, their
+// slices and Mat
+func (w *Worker) Marshal(x any) (*WorkerValue, error) {
+	req := &encoder{}
+	req.WriteByte(opPut)
+	if err := req.writeValue(x); err != nil {
+		return nil, err
+	}
+
+	return w.value(req)
+}
+
+// Unmarshal fetches value from worker process into x. Supported types
+// are a subset of those of package level Unmarshal, i.e. pointers to
+// primitive types, strings, slices of strings and Mat
+func (w *Worker) Unmarshal(data *WorkerValue, x any) error {
+	if err := w.check(data); err != nil {
+		return err
+	}
+
+	req := &encoder{}
+	req.WriteByte(opGet)
+	req.writeUint64(data.handle)
+
+	resp, err := w.roundTrip(req, data)
+	if err != nil {
+		return err
+	}
+
+	typeName, err := resp.readString()
+	if err != nil {
+		return err
+	}
+
+	// typed nil pointers only accept nothing, which carries no data
+	if isNilPointer(x) {
+		if typeName == "Nothing" {
+			return nil
+		}
+		return fmt.Errorf("%w: cannot unmarshal julia %s into nil %T", ErrTypeMismatch, typeName, x)
+	}
+
+	supported, err := resp.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if supported == 0 {
+		return fmt.Errorf("%w: cannot unmarshal julia %s into %T", ErrTypeMismatch, typeName, x)
+	}
+
+	value, err := resp.readValue()
+	if err != nil {
+		return err
+	}
+	value.typeName = typeName
+
+	return value.readInto(x)
+}
+
+// Ping checks if worker process is responsive, starting it if needed.
+// Pings are not counted as requests served by the process
+func (w *Worker) Ping() error {
+	return w.ping(0)
+}
+
+// ping is like Ping, however, worker process is killed if it does not
+// respond within timeout, in which case ErrWorkerUnresponsive is returned.
+// Zero timeout waits for the response indefinitely
+func (w *Worker) ping(timeout time.Duration) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	req := &encoder{}
+	req.WriteByte(opPing)
+
+	if timeout <= 0 {
+		_, err := w.exchange(req)
+		return err
+	}
+
+	// a hung process, such as one running an infinite loop, never writes
+	// the response, hence it is killed to unblock reading it
+	proc := w.proc
+	timer := time.AfterFunc(timeout, func() {
+		_ = proc.cmd.Process.Kill()
+	})
+
+	_, err := w.exchange(req)
+	if !timer.Stop() {
+		return fmt.Errorf("%w: no response to ping within %v", ErrWorkerUnresponsive, timeout)
+	}
+
+	return err
+}
+
+// Requests returns number of requests served by current worker process
+func (w *Worker) Requests() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.requests
+}
+
+// Type evaluates to julia representation of typeof
+func (v *WorkerValue) Type() string {
+	if err := v.w.check(v); err != nil {
+		return ""
+	}
+
+	req := &encoder{}
+	req.WriteByte(opType)
+	req.writeUint64(v.handle)
+
+	resp, err := v.w.roundTrip(req, v)
+	if err != nil {
+		return ""
+	}
+
+	typeName, _ := resp.readString()
+	return typeName
+}
+
+// Release frees the value in worker process. Value cannot be used
+// once released
+func (v *WorkerValue) Release() error {
+	if err := v.w.check(v); err != nil {
+		return err
+	}
+
+	req := &encoder{}
+	req.WriteByte(opRelease)
+	req.writeUint64(v.handle)
+
+	_, err := v.w.roundTrip(req, v)
+	return err
+}
+
+// check verifies that value belongs to this worker
+func (w *Worker) check(v *WorkerValue) error {
+	if v == nil || v.w != w {
+		return fmt.Errorf("value does not belong to this worker")
+	}
+
+	return nil
+}
+
+// value sends request expecting a handle in response
+func (w *Worker) value(req *encoder, args ...*WorkerValue) (*WorkerValue, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	resp, err := w.roundTripLocked(req, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := resp.readUint64()
+	if err != nil {
+		return nil, err
+	}
+
+	return &WorkerValue{handle: handle, gen: w.gen, w: w}, nil
+}
+
+// roundTrip sends request and returns decoder positioned after response
+// status. Values are checked to belong to current worker process
+func (w *Worker) roundTrip(req *encoder, values ...*WorkerValue) (*decoder, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.roundTripLocked(req, values...)
+}
+
+func (w *Worker) roundTripLocked(req *encoder, values ...*WorkerValue) (*decoder, error) {
+	if w.closed {
+		return nil, ErrWorkerClosed
+	}
+
+	if err := w.start(); err != nil {
+		return nil, err
+	}
+
+	for _, v := range values {
+		if v.gen != w.gen {
+			return nil, ErrStaleValue
+		}
+	}
+
+	w.requests++
+
+	return w.exchange(req)
+}
+
+// exchange writes request to worker process and reads its response
+func (w *Worker) exchange(req *encoder) (*decoder, error) {
+	if err := writeFrame(w.proc.stdin, req.Bytes()); err != nil {
+		return nil, w.crashed(err)
+	}
+
+	payload, err := readFrame(w.proc.stdout)
+	if err != nil {
+		return nil, w.crashed(err)
+	}
+
+	resp := newDecoder(payload)
+	status, err := resp.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case statusOK:
+		return resp, nil
+	case statusError:
+		return nil, resp.readError()
+	default:
+		return nil, fmt.Errorf("invalid response status %d", status)
+	}
+}
+
+// start starts worker process unless it is already running
+func (w *Worker) start() error {
+	if w.closed {
+		return ErrWorkerClosed
+	}
+
+	if w.proc != nil {
+		select {
+		case <-w.proc.done:
+			// process exited since last call, restart it
+			w.setProc(nil)
+		default:
+			return nil
+		}
+	}
+
+	name, args, err := w.command()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not start julia worker: %w", err)
+	}
+
+	proc := &workerProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		proc.err = cmd.Wait()
+		close(proc.done)
+	}()
+
+	w.setProc(proc)
+	w.gen++
+	w.requests = 0
+
+	for _, code := range w.preload {
+		req := &encoder{}
+		req.WriteByte(opEval)
+		req.writeString(code)
+
+		if _, err := w.exchange(req); err != nil {
+			if w.proc != nil {
+				_ = w.stop()
+			}
+			return fmt.Errorf("could not preload julia worker: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// recycle stops worker process, if running, so that next call starts
+// a fresh one
+func (w *Worker) recycle() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.proc == nil {
+		return nil
+	}
+
+	return w.stop()
+}
+
+// stop closes stdin of worker process, which makes julia exit its request
+// loop, and kills the process if it does not exit in time
+func (w *Worker) stop() error {
+	proc := w.proc
+	w.setProc(nil)
+
+	_ = proc.stdin.Close()
+
+	timer := time.NewTimer(workerCloseTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-proc.done:
+	case <-timer.C:
+		_ = proc.cmd.Process.Kill()
+		<-proc.done
+	}
+
+	var exitErr *exec.ExitError
+	if proc.err != nil && !errors.As(proc.err, &exitErr) {
+		return proc.err
+	}
+
+	return nil
+}
+
+// crashed kills worker process after a failed exchange and returns
+// an error describing why it terminated
+func (w *Worker) crashed(err error) error {
+	proc := w.proc
+	w.setProc(nil)
+
+	_ = proc.cmd.Process.Kill()
+	<-proc.done
+
+	if proc.err != nil {
+		return fmt.Errorf("%w: %v", ErrWorkerCrashed, proc.err)
+	}
+
+	return fmt.Errorf("%w: %v", ErrWorkerCrashed, err)
+}
+
+// command returns julia executable and its arguments for worker process
+func (w *Worker) command() (string, []string, error) {
+	args, err := w.opts.args()
+	if err != nil {
+		return "", nil, err
+	}
+
+	binDir, image, err := w.opts.image()
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := "julia"
+	if len(binDir) > 0 {
+		name = filepath.Join(binDir, "julia")
+	}
+
+	if len(image) > 0 {
+		if !filepath.IsAbs(image) {
+			image = filepath.Join(binDir, image)
+		}
+		args = append(args, "--sysimage="+image)
+	}
+
+	args = append([]string{"--startup-file=no", "--history-file=no"}, args...)
+	args = append(args, "-e", workerScript)
+
+	return name, args, nil
+}
@@ -0,0 +1,189 @@
+# julia side of the worker protocol, see protocol.go for framing.
+# requests are read from stdin and responses are written to stdout,
+# whereas any output of user code is redirected to stderr.
+module GoJuliaWorker
+
+const OP_EVAL = 0x01
+const OP_CALL = 0x02
+const OP_PUT = 0x03
+const OP_GET = 0x04
+const OP_TYPE = 0x05
+const OP_RELEASE = 0x06
+const OP_PING = 0x07
+
+const STATUS_OK = 0x00
+const STATUS_ERROR = 0x01
+
+const MODULE_BASE = 0x00
+
+const KINDS = Dict{UInt8,DataType}(
+    0x01 => Bool,
+    0x02 => UInt8,
+    0x03 => UInt16,
+    0x04 => UInt32,
+    0x05 => UInt64,
+    0x06 => Int8,
+    0x07 => Int16,
+    0x08 => Int32,
+    0x09 => Int64,
+    0x0a => Float32,
+    0x0b => Float64,
+    0x0c => ComplexF32,
+    0x0d => ComplexF64,
+    0x0e => Int128,
+    0x0f => UInt128,
+    0x10 => Float16,
+)
+
+const KIND_OF = Dict{DataType,UInt8}(v => k for (k, v) in KINDS)
+
+# strings are encoded by their length and bytes, hence they are not
+# listed among kinds read and written as bits
+const KIND_STRING = 0x11
+
+# values are kept referenced by their handles until released
+const VALUES = Dict{UInt64,Any}()
+const COUNTER = Ref{UInt64}(0)
+
+function store(x)
+    COUNTER[] += 1
+    VALUES[COUNTER[]] = x
+    return COUNTER[]
+end
+
+readhandle(io) = ltoh(read(io, UInt64))
+readstring(io) = String(read(io, Int(ltoh(read(io, UInt32)))))
+
+function writestring(io, s::AbstractString)
+    b = codeunits(String(s))
+    write(io, htol(UInt32(length(b))))
+    write(io, b)
+end
+
+function readvalue(io)
+    kind = read(io, UInt8)
+    rank = Int(read(io, UInt8))
+    dims = [Int(ltoh(read(io, UInt64))) for _ in 1:rank]
+    if kind == KIND_STRING
+        rank == 0 && return readstring(io)
+        return reshape([readstring(io) for _ in 1:prod(dims)], dims...)
+    end
+
+    T = KINDS[kind]
+    rank == 0 && return ltoh(read(io, T))
+
+    a = Array{T}(undef, dims...)
+    read!(io, a)
+    return a
+end
+
+# writedims writes rank and dims of an array or rank 0 of a scalar
+function writedims(io, x)
+    if x isa Array
+        write(io, UInt8(ndims(x)))
+        for d in size(x)
+            write(io, htol(UInt64(d)))
+        end
+    else
+        write(io, 0x00)
+    end
+end
+
+# writevalue writes supported flag followed by the encoded value
+function writevalue(io, x)
+    if x isa String || x isa Array{String}
+        write(io, 0x01)
+        write(io, KIND_STRING)
+        writedims(io, x)
+        foreach(s -> writestring(io, s), x isa String ? (x,) : x)
+        return
+    end
+
+    T = x isa Array ? eltype(x) : typeof(x)
+    if !haskey(KIND_OF, T) || !(x isa Array || isbits(x))
+        write(io, 0x00)
+        return
+    end
+
+    write(io, 0x01)
+    write(io, KIND_OF[T])
+    writedims(io, x)
+    if x isa Array
+        write(io, x)
+    else
+        write(io, htol(x))
+    end
+end
+
+function handle(req, resp)
+    op = read(req, UInt8)
+    if op == OP_EVAL
+        x = Core.eval(Main, Meta.parseall(readstring(req)))
+        write(resp, STATUS_OK)
+        write(resp, htol(store(x)))
+    elseif op == OP_CALL
+        m = read(req, UInt8) == MODULE_BASE ? Base : Main
+        f = getfield(m, Symbol(readstring(req)))
+        nargs = Int(ltoh(read(req, UInt32)))
+        args = [VALUES[readhandle(req)] for _ in 1:nargs]
+        # functions defined via eval are newer than this loop
+        x = Base.invokelatest(f, args...)
+        write(resp, STATUS_OK)
+        write(resp, htol(store(x)))
+    elseif op == OP_PUT
+        x = readvalue(req)
+        write(resp, STATUS_OK)
+        write(resp, htol(store(x)))
+    elseif op == OP_GET
+        x = VALUES[readhandle(req)]
+        write(resp, STATUS_OK)
+        writestring(resp, string(typeof(x)))
+        writevalue(resp, x)
+    elseif op == OP_TYPE
+        x = VALUES[readhandle(req)]
+        write(resp, STATUS_OK)
+        writestring(resp, string(typeof(x)))
+    elseif op == OP_RELEASE
+        delete!(VALUES, readhandle(req))
+        write(resp, STATUS_OK)
+    elseif op == OP_PING
+        write(resp, STATUS_OK)
+    else
+        error("unknown op $(op)")
+    end
+end
+
+function serve(in::IO, out::IO)
+    while !eof(in)
+        n = Int(ltoh(read(in, UInt32)))
+        req = IOBuffer(read(in, n))
+        resp = IOBuffer()
+        try
+            handle(req, resp)
+        catch e
+            bt = catch_backtrace()
+            resp = IOBuffer()
+            write(resp, STATUS_ERROR)
+            writestring(resp, string(nameof(typeof(e))))
+            writestring(resp, sprint(showerror, e))
+            writestring(resp, sprint(Base.show_backtrace, bt))
+        end
+        b = take!(resp)
+        write(out, htol(UInt32(length(b))))
+        write(out, b)
+        flush(out)
+    end
+end
+
+function main()
+    # keep protocol streams on duplicated descriptors before
+    # redirecting stdout, so that printing does not corrupt frames
+    in = fdio(ccall(:dup, Cint, (Cint,), 0), true)
+    out = fdio(ccall(:dup, Cint, (Cint,), 1), true)
+    redirect_stdout(stderr)
+    serve(in, out)
+end
+
+end # module
+
+GoJuliaWorker.main()
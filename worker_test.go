@@ -0,0 +1,131 @@
+package julia
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+// newTestWorker returns a worker or skips the test if julia executable
+// is not available
+func newTestWorker(t *testing.T) *Worker {
+	if _, err := exec.LookPath("julia"); err != nil {
+		t.Skip("julia executable not found")
+	}
+
+	w := NewWorker(nil)
+	t.Cleanup(func() {
+		_ = w.Close()
+	})
+
+	return w
+}
+
+func TestWorkerInverse(t *testing.T) {
+	w := newTestWorker(t)
+
+	x, err := NewMat([]float64{4, 0, 0, 2}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arg, err := w.Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := w.EvalFunc("inv", ModuleBase, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Type() != "Matrix{Float64}" {
+		t.Fatal("expected Matrix{Float64}, got", resp.Type())
+	}
+
+	y := new(Mat[float64])
+	if err := w.Unmarshal(resp, y); err != nil {
+		t.Fatal(err)
+	}
+
+	if y.GetElms()[0] != 0.25 || y.GetElms()[3] != 0.5 {
+		t.Fatal("did not receive expected values", y.GetElms())
+	}
+}
+
+func TestWorkerJuliaError(t *testing.T) {
+	w := newTestWorker(t)
+
+	if _, err := w.Eval("sqrt(-1.0)"); !errors.Is(err, &JuliaError{Type: "DomainError"}) {
+		t.Fatal("expected DomainError, got", err)
+	}
+
+	if _, err := w.Eval("println(\"output of user code goes to stderr\"); 1 + 1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkerCrashRestart(t *testing.T) {
+	w := newTestWorker(t)
+
+	value, err := w.Eval("f(x) = 2x; f(21)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Eval("exit(1)"); !errors.Is(err, ErrWorkerCrashed) {
+		t.Fatal("expected ErrWorkerCrashed, got", err)
+	}
+
+	var n int64
+	if err := w.Unmarshal(value, &n); !errors.Is(err, ErrStaleValue) {
+		t.Fatal("expected ErrStaleValue, got", err)
+	}
+
+	value, err = w.Eval("21 + 21")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Unmarshal(value, &n); err != nil || n != 42 {
+		t.Fatal("expected 42 from restarted worker, got", n, err)
+	}
+}
+
+func TestWorkerStrings(t *testing.T) {
+	w := newTestWorker(t)
+
+	arg, err := w.Marshal([]string{"a", "bc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if arg.Type() != "Vector{String}" {
+		t.Fatal("expected Vector{String}, got", arg.Type())
+	}
+
+	sep, err := w.Marshal("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := w.EvalFunc("join", ModuleBase, arg, sep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s string
+	if err := w.Unmarshal(resp, &s); err != nil || s != "a-bc" {
+		t.Fatal("expected a-bc, got", s, err)
+	}
+
+	resp, err = w.Eval(`["x", "yz"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var list []string
+	if err := w.Unmarshal(resp, &list); err != nil || len(list) != 2 || list[1] != "yz" {
+		t.Fatal("expected [x yz], got", list, err)
+	}
+}